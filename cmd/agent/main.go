@@ -2,29 +2,63 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/ai-agent-framework/pkg/agent"
 	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/planfile"
+	"github.com/ai-agent-framework/pkg/planner"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Load configuration from environment variables
 	config := &agent.Config{
-		OllamaURL:       getEnv("OLLAMA_URL", "http://localhost:11434"),
-		LLMModel:        getEnv("LLM_MODEL", "deepseek-r1:latest"),
-		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		BrowserHeadless: getEnvBool("BROWSER_HEADLESS", true),
-		MemoryType:      getEnv("MEMORY_TYPE", "memory"),
+		OllamaURL:             getEnv("OLLAMA_URL", "http://localhost:11434"),
+		LLMModel:              getEnv("LLM_MODEL", "deepseek-r1:latest"),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		BrowserHeadless:       getEnvBool("BROWSER_HEADLESS", true),
+		MemoryType:            getEnv("MEMORY_TYPE", "memory"),
+		MaxParallelTasks:      getEnvInt("MAX_PARALLEL_TASKS", 4),
+		PlanTimeout:           getEnvDuration("PLAN_TIMEOUT", 30*time.Minute),
+		ScriptAllowedCommands: getEnvList("SCRIPT_ALLOWED_COMMANDS"),
+		ScriptWorkingDirRoot:  os.Getenv("SCRIPT_WORKING_DIR_ROOT"),
+		ReplanOnFailure:       getEnvBool("REPLAN_ON_FAILURE", false),
+		MaxReplans:            getEnvInt("MAX_REPLANS", 2),
+		MaxAgentSteps:         getEnvInt("MAX_AGENT_STEPS", 15),
+		LLMProvider:           getEnv("LLM_PROVIDER", "ollama"),
+		LLMBaseURL:            os.Getenv("LLM_BASE_URL"),
+		LLMAPIKey:             os.Getenv("LLM_API_KEY"),
+		LLMCassetteMode:       os.Getenv("LLM_CASSETTE_MODE"),
+		LLMCassettePath:       os.Getenv("LLM_CASSETTE_PATH"),
+		LLMTimeout:            getEnvDuration("LLM_TIMEOUT", 60*time.Second),
+		TokenBudget:           getEnvInt("TOKEN_BUDGET", 0),
+		ReasoningDir:          os.Getenv("LLM_REASONING_DIR"),
+		LLMResilience: llm.ResilienceConfig{
+			MaxAttempts:      getEnvInt("LLM_MAX_ATTEMPTS", 3),
+			FailureThreshold: getEnvInt("LLM_CIRCUIT_THRESHOLD", 5),
+			OpenDuration:     getEnvDuration("LLM_CIRCUIT_OPEN_DURATION", 30*time.Second),
+			MaxConcurrent:    getEnvInt("LLM_MAX_CONCURRENT", 2),
+			MaxQueued:        getEnvInt("LLM_MAX_QUEUED", 100),
+		},
 	}
 
+	modelRoutes, err := llm.ParseModelRoutes(os.Getenv("LLM_MODEL_ROUTES"))
+	if err != nil {
+		log.Fatalf("Failed to read LLM_MODEL_ROUTES: %v", err)
+	}
+	config.ModelRoutes = modelRoutes
+
 	// Create agent framework
 	framework, err := agent.NewFramework(config)
 	if err != nil {
@@ -99,6 +133,12 @@ func setupRouter(framework interfaces.AgentFramework) *gin.Engine {
 		v1.POST("/goals", func(c *gin.Context) {
 			var request struct {
 				Goal string `json:"goal" binding:"required"`
+				// Mode "iterative" chooses one task at a time instead of planning upfront
+				Mode string `json:"mode"`
+				// Stream sends the LLM output as server-sent events while planning
+				Stream bool `json:"stream"`
+				// TokenBudget caps the LLM tokens of the goal, overriding TOKEN_BUDGET
+				TokenBudget int `json:"token_budget"`
 			}
 
 			if err := c.ShouldBindJSON(&request); err != nil {
@@ -106,8 +146,72 @@ func setupRouter(framework interfaces.AgentFramework) *gin.Engine {
 				return
 			}
 
-			plan, err := framework.ExecuteGoal(c.Request.Context(), request.Goal)
+			withBudget := func(ctx context.Context) context.Context {
+				if request.TokenBudget > 0 {
+					return agent.WithTokenBudget(ctx, request.TokenBudget)
+				}
+				return ctx
+			}
+
+			var execute func(ctx context.Context) (*interfaces.Plan, error)
+			switch request.Mode {
+			case "", "plan":
+				execute = func(ctx context.Context) (*interfaces.Plan, error) {
+					return framework.ExecuteGoal(withBudget(ctx), request.Goal)
+				}
+			case "iterative":
+				execute = func(ctx context.Context) (*interfaces.Plan, error) {
+					return framework.ExecuteGoalIterative(withBudget(ctx), request.Goal)
+				}
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown mode " + strconv.Quote(request.Mode) + "; use \"plan\" or \"iterative\""})
+				return
+			}
+
+			if request.Stream {
+				streamGoal(c, execute)
+				return
+			}
+
+			plan, err := execute(c.Request.Context())
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{
+				"plan_id": plan.ID,
+				"goal":    plan.Goal,
+				"tasks":   len(plan.Tasks),
+				"status":  plan.Status,
+				"usage":   plan.Usage,
+			})
+		})
+
+		// Execute a hand-authored plan (YAML or JSON plan file) without the planner
+		v1.POST("/plans", func(c *gin.Context) {
+			data, err := c.GetRawData()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			file, err := planfile.Parse(data)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			plan, err := file.Plan(framework)
+			if err == nil {
+				plan, err = framework.ExecutePlan(c.Request.Context(), plan)
+			}
 			if err != nil {
+				var validationErr *planner.PlanValidationError
+				if errors.As(err, &validationErr) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "problems": validationErr.Problems})
+					return
+				}
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
@@ -134,6 +238,53 @@ func setupRouter(framework interfaces.AgentFramework) *gin.Engine {
 	return router
 }
 
+// streamGoal runs execute while streaming the generated LLM output to the
+// client as "token" events, and the reasoning of thinking models as
+// "reasoning" events, followed by a "plan" or "error" event
+func streamGoal(c *gin.Context, execute func(ctx context.Context) (*interfaces.Plan, error)) {
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	// Plan execution may call the LLM after the response is finished, so
+	// chunks are only written while the request is being served
+	var mu sync.Mutex
+	open := true
+	ctx := llm.WithChunkHandler(c.Request.Context(), func(chunk interfaces.LLMChunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !open || (chunk.Response == "" && chunk.Reasoning == "") {
+			return
+		}
+		if chunk.Reasoning != "" {
+			c.SSEvent("reasoning", chunk.Reasoning)
+		}
+		if chunk.Response != "" {
+			c.SSEvent("token", chunk.Response)
+		}
+		c.Writer.Flush()
+	})
+
+	plan, err := execute(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	open = false
+
+	if err != nil {
+		c.SSEvent("error", gin.H{"error": err.Error()})
+		c.Writer.Flush()
+		return
+	}
+
+	c.SSEvent("plan", gin.H{
+		"plan_id": plan.ID,
+		"goal":    plan.Goal,
+		"tasks":   len(plan.Tasks),
+		"status":  plan.Status,
+	})
+	c.Writer.Flush()
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -149,3 +300,31 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
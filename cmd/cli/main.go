@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/ai-agent-framework/pkg/agent"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/planfile"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +19,23 @@ var (
 	logLevel        string
 	browserHeadless bool
 	memoryType      string
+	maxParallel     int
+	planTimeout     time.Duration
+	allowedCommands []string
+	replan          bool
+	maxReplans      int
+	maxSteps        int
+	modelRoutes     string
+	stream          bool
+	llmProvider     string
+	llmBaseURL      string
+	recordLLM       string
+	replayLLM       string
+	llmTimeout      time.Duration
+	llmAttempts     int
+	llmConcurrency  int
+	tokenBudget     int
+	saveReasoning   string
 )
 
 func main() {
@@ -27,14 +48,32 @@ func main() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&ollamaURL, "ollama-url", "http://localhost:11434", "Ollama API URL")
 	rootCmd.PersistentFlags().StringVar(&llmModel, "llm-model", "deepseek-r1:latest", "LLM model to use")
+	rootCmd.PersistentFlags().StringVar(&llmProvider, "llm-provider", "ollama", "LLM backend (ollama, openai)")
+	rootCmd.PersistentFlags().StringVar(&llmBaseURL, "llm-base-url", "", "API base URL of the openai provider, e.g. http://localhost:8000/v1")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().BoolVar(&browserHeadless, "headless", true, "Run browser in headless mode")
 	rootCmd.PersistentFlags().StringVar(&memoryType, "memory-type", "memory", "Memory backend type")
+	rootCmd.PersistentFlags().IntVar(&maxParallel, "max-parallel", 4, "Maximum number of plan tasks to run concurrently")
+	rootCmd.PersistentFlags().StringSliceVar(&allowedCommands, "allow-command", nil, "Command script tasks may run (repeatable, replaces the default allowlist)")
+	rootCmd.PersistentFlags().DurationVar(&planTimeout, "plan-timeout", 30*time.Minute, "Maximum total execution time of a plan (0 disables it)")
+	rootCmd.PersistentFlags().BoolVar(&replan, "replan", false, "Revise and resume the plan when tasks fail")
+	rootCmd.PersistentFlags().IntVar(&maxReplans, "max-replans", 2, "Maximum number of plan revisions when --replan is set")
+	rootCmd.PersistentFlags().BoolVar(&stream, "stream", false, "Print the LLM output live while planning")
+	rootCmd.PersistentFlags().DurationVar(&llmTimeout, "llm-timeout", 60*time.Second, "Timeout of LLM requests that are not streamed")
+	rootCmd.PersistentFlags().IntVar(&llmAttempts, "llm-max-attempts", 3, "Attempts per LLM request on server and connection errors")
+	rootCmd.PersistentFlags().IntVar(&llmConcurrency, "llm-max-concurrent", 2, "Maximum number of LLM requests sent at once; others wait in a queue")
+	rootCmd.PersistentFlags().StringVar(&recordLLM, "record-llm", "", "Record every LLM request and response to this cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayLLM, "replay-llm", "", "Serve LLM responses from this cassette file instead of a model server")
+	rootCmd.PersistentFlags().StringVar(&modelRoutes, "model-routes", "", `Model and options per LLM stage as JSON, e.g. {"planning": "qwen2.5:14b"}`)
+	rootCmd.PersistentFlags().IntVar(&tokenBudget, "token-budget", 0, "Maximum number of LLM tokens a goal or plan may use (0 is unlimited)")
+	rootCmd.PersistentFlags().StringVar(&saveReasoning, "save-reasoning", "", "Save the reasoning of thinking models below this directory, grouped by task")
+	rootCmd.PersistentFlags().IntVar(&maxSteps, "max-steps", 15, "Maximum number of tasks run for a goal executed with --iterative")
 
 	// Add commands
 	rootCmd.AddCommand(planCmd())
 	rootCmd.AddCommand(statusCmd())
 	rootCmd.AddCommand(executeCmd())
+	rootCmd.AddCommand(runPlanCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -61,7 +100,7 @@ func planCmd() *cobra.Command {
 			}
 			defer framework.Stop(ctx)
 
-			plan, err := framework.ExecuteGoal(ctx, goal)
+			plan, err := framework.ExecuteGoal(streamContext(ctx), goal)
 			if err != nil {
 				return fmt.Errorf("failed to create plan: %w", err)
 			}
@@ -71,6 +110,9 @@ func planCmd() *cobra.Command {
 			fmt.Printf("Goal: %s\n", plan.Goal)
 			fmt.Printf("Tasks: %d\n", len(plan.Tasks))
 			fmt.Printf("Status: %s\n", plan.Status)
+			if plan.Usage != nil {
+				fmt.Printf("LLM tokens: %d (%d prompt, %d completion)\n", plan.Usage.TotalTokens, plan.Usage.PromptTokens, plan.Usage.CompletionTokens)
+			}
 
 			fmt.Printf("\nTasks:\n")
 			for i, task := range plan.Tasks {
@@ -114,7 +156,9 @@ func statusCmd() *cobra.Command {
 }
 
 func executeCmd() *cobra.Command {
-	return &cobra.Command{
+	var iterative bool
+
+	cmd := &cobra.Command{
 		Use:   "execute [goal]",
 		Short: "Execute a goal and monitor progress",
 		Args:  cobra.ExactArgs(1),
@@ -134,32 +178,156 @@ func executeCmd() *cobra.Command {
 
 			fmt.Printf("Executing goal: %s\n", goal)
 
-			plan, err := framework.ExecuteGoal(ctx, goal)
+			if iterative {
+				plan, err := framework.ExecuteGoalIterative(ctx, goal)
+				if err != nil {
+					return fmt.Errorf("failed to execute goal: %w", err)
+				}
+
+				fmt.Printf("Iterative execution started!\n")
+				fmt.Printf("Plan ID: %s\n", plan.ID)
+			} else {
+				plan, err := framework.ExecuteGoal(streamContext(ctx), goal)
+				if err != nil {
+					return fmt.Errorf("failed to execute goal: %w", err)
+				}
+
+				fmt.Printf("Plan created and execution started!\n")
+				fmt.Printf("Plan ID: %s\n", plan.ID)
+				fmt.Printf("Tasks: %d\n", len(plan.Tasks))
+			}
+
+			// In a real implementation, you'd monitor progress here
+			fmt.Printf("Monitoring execution... (Press Ctrl+C to stop)\n")
+
+			// Simple wait - in reality you'd subscribe to events
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+
+	cmd.Flags().BoolVar(&iterative, "iterative", false, "Choose one task at a time from the observed results instead of planning upfront")
+
+	return cmd
+}
+
+func runPlanCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "run-plan [file]",
+		Short: "Execute a hand-authored YAML or JSON plan file without the planner",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := planfile.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			framework, err := createFramework()
+			if err != nil {
+				return fmt.Errorf("failed to create framework: %w", err)
+			}
+
+			plan, err := file.Plan(framework)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("Plan file is valid\n")
+				fmt.Printf("Goal: %s\n", plan.Goal)
+				fmt.Printf("\nTasks:\n")
+				for i, task := range plan.Tasks {
+					fmt.Printf("%d. %s [%s] %s\n", i+1, task.Name, task.Type, task.Description)
+				}
+				return nil
+			}
+
+			ctx := context.Background()
+			if err := framework.Start(ctx); err != nil {
+				return fmt.Errorf("failed to start framework: %w", err)
+			}
+			defer framework.Stop(ctx)
+
+			plan, err = framework.ExecutePlan(ctx, plan)
 			if err != nil {
-				return fmt.Errorf("failed to execute goal: %w", err)
+				return fmt.Errorf("failed to execute plan: %w", err)
 			}
 
-			fmt.Printf("Plan created and execution started!\n")
+			fmt.Printf("Plan execution started!\n")
 			fmt.Printf("Plan ID: %s\n", plan.ID)
 			fmt.Printf("Tasks: %d\n", len(plan.Tasks))
 
-			// In a real implementation, you'd monitor progress here
 			fmt.Printf("Monitoring execution... (Press Ctrl+C to stop)\n")
 
-			// Simple wait - in reality you'd subscribe to events
 			<-ctx.Done()
 			return ctx.Err()
 		},
 	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the plan file and list its tasks without executing it")
+
+	return cmd
+}
+
+// streamContext prints the LLM output of calls made with the returned context
+// when --stream is set
+func streamContext(ctx context.Context) context.Context {
+	if !stream {
+		return ctx
+	}
+
+	return llm.WithChunkHandler(ctx, func(chunk interfaces.LLMChunk) {
+		fmt.Print(chunk.Response)
+		if chunk.Done {
+			fmt.Println()
+		}
+	})
 }
 
 func createFramework() (*agent.Framework, error) {
+	routes, err := llm.ParseModelRoutes(modelRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassetteMode, cassettePath string
+	switch {
+	case recordLLM != "" && replayLLM != "":
+		return nil, fmt.Errorf("--record-llm and --replay-llm cannot be combined")
+	case recordLLM != "":
+		cassetteMode, cassettePath = llm.CassetteRecord, recordLLM
+	case replayLLM != "":
+		cassetteMode, cassettePath = llm.CassetteReplay, replayLLM
+	}
+
 	config := &agent.Config{
-		OllamaURL:       ollamaURL,
-		LLMModel:        llmModel,
-		LogLevel:        logLevel,
-		BrowserHeadless: browserHeadless,
-		MemoryType:      memoryType,
+		OllamaURL:             ollamaURL,
+		LLMModel:              llmModel,
+		LogLevel:              logLevel,
+		BrowserHeadless:       browserHeadless,
+		MemoryType:            memoryType,
+		MaxParallelTasks:      maxParallel,
+		PlanTimeout:           planTimeout,
+		ScriptAllowedCommands: allowedCommands,
+		ReplanOnFailure:       replan,
+		MaxReplans:            maxReplans,
+		MaxAgentSteps:         maxSteps,
+		ModelRoutes:           routes,
+		LLMProvider:           llmProvider,
+		LLMBaseURL:            llmBaseURL,
+		// Read from the environment to keep the key out of shell history
+		LLMAPIKey:       os.Getenv("LLM_API_KEY"),
+		LLMCassetteMode: cassetteMode,
+		LLMCassettePath: cassettePath,
+		LLMTimeout:      llmTimeout,
+		TokenBudget:     tokenBudget,
+		ReasoningDir:    saveReasoning,
+		LLMResilience: llm.ResilienceConfig{
+			MaxAttempts:   llmAttempts,
+			MaxConcurrent: llmConcurrency,
+		},
 	}
 
 	return agent.NewFramework(config)
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/ai-agent-framework/pkg/llm"
 	"github.com/ai-agent-framework/pkg/logger"
 	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/ai-agent-framework/pkg/planfile"
 	"github.com/ai-agent-framework/pkg/planner"
 )
 
@@ -24,6 +26,7 @@ type Framework struct {
 	memory       interfaces.MemoryStore
 	langGraph    interfaces.LangGraphEngine
 	llmClient    interfaces.LLMClient
+	usage        *llm.MeteredClient
 	eventBus     interfaces.EventBus
 	logger       interfaces.Logger
 	
@@ -32,6 +35,10 @@ type Framework struct {
 	
 	// Runtime state
 	isRunning bool
+	
+	// runs is the parent of background plan runs; Stop cancels it
+	runs     context.Context
+	stopRuns context.CancelFunc
 }
 
 // Config holds the framework configuration
@@ -41,6 +48,68 @@ type Config struct {
 	LogLevel       string
 	BrowserHeadless bool
 	MemoryType     string
+
+	// MaxParallelTasks limits how many independent plan tasks run at once
+	MaxParallelTasks int
+	
+	// PlanTimeout bounds the total execution time of a plan (0 disables it)
+	PlanTimeout time.Duration
+	
+	// ScriptAllowedCommands overrides the commands script tasks may run
+	ScriptAllowedCommands []string
+	
+	// ScriptWorkingDirRoot overrides the directory script tasks run in and
+	// may read from (default: the results directory)
+	ScriptWorkingDirRoot string
+	
+	// ReplanOnFailure revises and resumes a plan when tasks fail instead of
+	// failing the plan
+	ReplanOnFailure bool
+	
+	// MaxReplans caps how often a plan is revised (default 2)
+	MaxReplans int
+	
+	// MaxAgentSteps caps the tasks run for a goal executed iteratively
+	// (default 15)
+	MaxAgentSteps int
+	
+	// ModelRoutes selects the model and generation options per LLM stage;
+	// stages without a route use LLMModel
+	ModelRoutes map[string]llm.ModelRoute
+	
+	// LLMProvider selects the LLM backend: "ollama" (default) or "openai" for
+	// servers exposing the OpenAI chat completions API, such as llama.cpp
+	// server and vLLM
+	LLMProvider string
+	
+	// LLMBaseURL is the API base URL of the "openai" provider including the
+	// version prefix, e.g. http://localhost:8000/v1
+	LLMBaseURL string
+	
+	// LLMAPIKey is sent as a bearer token to the "openai" provider
+	LLMAPIKey string
+	
+	// LLMCassetteMode "record" writes every LLM request and response to the
+	// cassette at LLMCassettePath; "replay" serves them back from it without
+	// a model server, failing on requests that were not recorded
+	LLMCassetteMode string
+	LLMCassettePath string
+	
+	// LLMTimeout bounds LLM requests whose responses are not streamed
+	// (default 60s)
+	LLMTimeout time.Duration
+	
+	// LLMResilience configures retries, the circuit breaker and the request
+	// queue in front of the LLM server; zero fields take their defaults
+	LLMResilience llm.ResilienceConfig
+	
+	// TokenBudget caps the LLM tokens each goal or plan may use (0 is
+	// unlimited); WithTokenBudget overrides it per call
+	TokenBudget int
+	
+	// ReasoningDir, if set, receives the reasoning of thinking models as
+	// text files, grouped by task, for debugging
+	ReasoningDir string
 }
 
 // NewFramework creates a new agent framework with all components
@@ -61,7 +130,21 @@ func NewFramework(config *Config) (*Framework, error) {
 	eventBus := eventbus.NewInMemoryEventBus(logger)
 	
 	// Initialize LLM client
-	llmClient := llm.NewOllamaClientWithModel(config.OllamaURL, config.LLMModel, logger)
+	llmClient, err := newLLMClient(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.ModelRoutes) > 0 {
+		if err := llm.ValidateModelRoutes(config.ModelRoutes); err != nil {
+			return nil, err
+		}
+		llmClient = llm.NewRoutedClient(llmClient, config.ModelRoutes, logger)
+	}
+	if config.ReasoningDir != "" {
+		llmClient = llm.NewReasoningArchive(llmClient, config.ReasoningDir, logger)
+	}
+	usage := llm.NewMeteredClient(llmClient, logger)
+	llmClient = usage
 	
 	// Initialize planner
 	taskPlanner := planner.NewTaskPlanner(llmClient, memoryStore, logger)
@@ -82,6 +165,7 @@ func NewFramework(config *Config) (*Framework, error) {
 		memory:       memoryStore,
 		langGraph:    langGraphEngine,
 		llmClient:    llmClient,
+		usage:        usage,
 		eventBus:     eventBus,
 		logger:       logger,
 		config:       config,
@@ -91,17 +175,64 @@ func NewFramework(config *Config) (*Framework, error) {
 	// Register task handlers
 	framework.registerTaskHandlers()
 	
+	// Let the planner offer the registered task types and parameter schemas
+	taskPlanner.SetHandlerCatalog(taskExecutor)
+	
 	logger.Info("Agent framework initialized successfully")
 	
 	return framework, nil
 }
 
+// newLLMClient creates the client of the configured LLM provider behind a
+// resilient client, recording or replaced by a replay of its calls in
+// cassette mode
+func newLLMClient(config *Config, logger interfaces.Logger) (interfaces.LLMClient, error) {
+	var client interfaces.LLMClient
+	switch config.LLMProvider {
+	case "", "ollama":
+		ollama := llm.NewOllamaClientWithModel(config.OllamaURL, config.LLMModel, logger)
+		if config.LLMTimeout > 0 {
+			ollama.SetTimeout(config.LLMTimeout)
+		}
+		client = ollama
+	case "openai":
+		if config.LLMBaseURL == "" {
+			return nil, fmt.Errorf("the openai LLM provider requires a base URL")
+		}
+		openAI := llm.NewOpenAIClient(config.LLMBaseURL, config.LLMAPIKey, config.LLMModel, logger)
+		if config.LLMTimeout > 0 {
+			openAI.SetTimeout(config.LLMTimeout)
+		}
+		client = openAI
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q; use ollama or openai", config.LLMProvider)
+	}
+	client = llm.NewResilientClient(client, config.LLMResilience, logger)
+
+	if config.LLMCassetteMode != "" && config.LLMCassettePath == "" {
+		return nil, fmt.Errorf("LLM cassette mode %q requires a cassette path", config.LLMCassetteMode)
+	}
+	switch config.LLMCassetteMode {
+	case "":
+		return client, nil
+	case llm.CassetteRecord:
+		return llm.NewRecordingClient(client, config.LLMCassettePath, logger)
+	case llm.CassetteReplay:
+		return llm.NewReplayClient(config.LLMCassettePath, logger)
+	default:
+		return nil, fmt.Errorf("unknown LLM cassette mode %q; use record or replay", config.LLMCassetteMode)
+	}
+}
+
 // Start initializes and starts the agent framework
 func (f *Framework) Start(ctx context.Context) error {
 	f.logger.Info("Starting agent framework")
 	
 	// Check LLM health
 	if !f.llmClient.IsHealthy(ctx) {
+		if f.config.LLMProvider == "openai" {
+			return fmt.Errorf("LLM client is not healthy - ensure the server at %s is running and accepts the API key", f.config.LLMBaseURL)
+		}
 		return fmt.Errorf("LLM client is not healthy - ensure Ollama is running on %s", f.config.OllamaURL)
 	}
 	
@@ -113,6 +244,7 @@ func (f *Framework) Start(ctx context.Context) error {
 	// Start event monitoring
 	f.startEventMonitoring(ctx)
 	
+	f.runs, f.stopRuns = context.WithCancel(context.Background())
 	f.isRunning = true
 	f.logger.Info("Agent framework started successfully")
 	
@@ -123,6 +255,11 @@ func (f *Framework) Start(ctx context.Context) error {
 func (f *Framework) Stop(ctx context.Context) error {
 	f.logger.Info("Stopping agent framework")
 	
+	// Cancel plans still running in the background
+	if f.stopRuns != nil {
+		f.stopRuns()
+	}
+	
 	// Close browser agent
 	if err := f.browserAgent.Close(ctx); err != nil {
 		f.logger.WithField("error", err).Warn("Failed to close browser agent")
@@ -139,7 +276,9 @@ func (f *Framework) Stop(ctx context.Context) error {
 	return nil
 }
 
-// ExecuteGoal creates a plan for the goal and executes it
+// ExecuteGoal creates a plan for the goal and executes it in the background.
+// Cancelling ctx stops planning but not the run, which ends with Stop. The
+// returned plan is a snapshot taken as the run starts.
 func (f *Framework) ExecuteGoal(ctx context.Context, goal string) (*interfaces.Plan, error) {
 	f.logger.WithField("goal", goal).Info("Executing goal")
 	
@@ -147,15 +286,67 @@ func (f *Framework) ExecuteGoal(ctx context.Context, goal string) (*interfaces.P
 		return nil, fmt.Errorf("framework is not running")
 	}
 	
+	// Meter the LLM usage of planning and execution together
+	ctx = f.meterPlan(ctx)
+	
 	// Create plan
 	plan, err := f.planner.CreatePlan(ctx, goal)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create plan: %w", err)
 	}
+	recordUsage(ctx, plan)
+	
+	f.startWorkflow(ctx, plan)
+	
+	// Start plan execution
+	snapshot := startRun(plan)
+	go f.executePlan(ctx, plan)
+	
+	return snapshot, nil
+}
+
+// ExecutePlan validates a prepared plan against the registered handlers and
+// executes it in the background without involving the planner. The given plan
+// is updated by the run; the returned one is a snapshot taken as it starts.
+func (f *Framework) ExecutePlan(ctx context.Context, plan *interfaces.Plan) (*interfaces.Plan, error) {
+	f.logger.WithFields(map[string]interface{}{
+		"plan_id": plan.ID,
+		"tasks":   len(plan.Tasks),
+	}).Info("Executing prepared plan")
+	
+	if !f.isRunning {
+		return nil, fmt.Errorf("framework is not running")
+	}
+	
+	if err := planfile.Validate(plan.Tasks, f.executor.DescribeHandlers()); err != nil {
+		return nil, err
+	}
+	
+	ctx = f.meterPlan(ctx)
+	
+	// Store the plan so it can be looked up and revised like a generated one
+	if err := f.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
+		f.logger.WithField("error", err).Warn("Failed to store plan in memory")
+	}
 	
+	f.startWorkflow(ctx, plan)
+	
+	snapshot := startRun(plan)
+	go f.executePlan(ctx, plan)
+	
+	return snapshot, nil
+}
+
+// DescribeHandlers lists the task types the framework can execute
+func (f *Framework) DescribeHandlers() []interfaces.HandlerDescription {
+	return f.executor.DescribeHandlers()
+}
+
+// startWorkflow creates the state machine tracking a plan and moves it to running
+func (f *Framework) startWorkflow(ctx context.Context, plan *interfaces.Plan) {
 	// Create workflow for plan execution
 	workflowID := "plan:" + plan.ID
-	states := []string{"pending", "running", "completed", "failed"}
+	states := []string{"pending", "running", "completed", "failed", "timed_out"}
 	
 	if err := f.langGraph.CreateWorkflow(ctx, workflowID, states); err != nil {
 		f.logger.WithField("error", err).Warn("Failed to create workflow")
@@ -164,9 +355,10 @@ func (f *Framework) ExecuteGoal(ctx context.Context, goal string) (*interfaces.P
 	// Add workflow transitions
 	transitions := map[string]map[string]string{
 		"pending":   {"start": "running"},
-		"running":   {"complete": "completed", "fail": "failed"},
+		"running":   {"complete": "completed", "fail": "failed", "timeout": "timed_out"},
 		"completed": {},
 		"failed":    {"retry": "pending"},
+		"timed_out": {"retry": "pending"},
 	}
 	
 	for from, events := range transitions {
@@ -175,16 +367,11 @@ func (f *Framework) ExecuteGoal(ctx context.Context, goal string) (*interfaces.P
 		}
 	}
 	
-	// Start plan execution
-	go f.executePlan(ctx, plan)
-	
-	// Trigger workflow start
+	// Trigger workflow start before execution so completion cannot race it
 	f.langGraph.TriggerEvent(ctx, workflowID, "start", map[string]interface{}{
 		"plan_id": plan.ID,
-		"goal":    goal,
+		"goal":    plan.Goal,
 	})
-	
-	return plan, nil
 }
 
 // GetStatus returns the current status of the framework
@@ -192,6 +379,7 @@ func (f *Framework) GetStatus(ctx context.Context) (map[string]interface{}, erro
 	status := map[string]interface{}{
 		"running":     f.isRunning,
 		"llm_healthy": f.llmClient.IsHealthy(ctx),
+		"llm_usage":   f.usage.Usage(),
 		"timestamp":   time.Now(),
 	}
 	
@@ -211,50 +399,161 @@ func (f *Framework) GetStatus(ctx context.Context) (map[string]interface{}, erro
 // registerTaskHandlers registers handlers for different task types
 func (f *Framework) registerTaskHandlers() {
 	// Register task handlers
+	// Navigation and element waits fail transiently, so browser tasks are
+	// retried on timeouts and network errors by default
 	browserHandler := executor.NewBrowserTaskHandler(f.browserAgent, f.logger)
-	f.executor.RegisterHandler("browser", browserHandler)
+	f.executor.RegisterHandler("browser", browserHandler,
+		executor.WithRetryPolicy(executor.DefaultRetryPolicy(3)),
+		executor.WithTimeout(2*time.Minute))
 	
 	// Register script handler
-	scriptHandler := executor.NewScriptTaskHandler(f.logger)
-	f.executor.RegisterHandler("script", scriptHandler)
+	scriptConfig := executor.DefaultScriptConfig()
+	if len(f.config.ScriptAllowedCommands) > 0 {
+		scriptConfig.AllowedCommands = f.config.ScriptAllowedCommands
+	}
+	if f.config.ScriptWorkingDirRoot != "" {
+		scriptConfig.WorkingDirRoot = f.config.ScriptWorkingDirRoot
+	}
+	scriptHandler := executor.NewScriptTaskHandlerWithConfig(scriptConfig, f.logger)
+	f.executor.RegisterHandler("script", scriptHandler, executor.WithTimeout(5*time.Minute))
 	
 	// Register analysis handler
-	analysisHandler := executor.NewAnalysisTaskHandler(f.logger, f.memory)
-	f.executor.RegisterHandler("analysis", analysisHandler)
+	analysisHandler := executor.NewAnalysisTaskHandler(f.logger, f.memory, f.llmClient)
+	f.executor.RegisterHandler("analysis", analysisHandler,
+		executor.WithRetryPolicy(executor.DefaultRetryPolicy(3)),
+		executor.WithTimeout(5*time.Minute))
+	
+	// Register API handler
+	apiHandler := executor.NewAPITaskHandler(f.logger)
+	f.executor.RegisterHandler("api", apiHandler,
+		executor.WithRetryPolicy(executor.DefaultRetryPolicy(3)),
+		executor.WithTimeout(time.Minute))
 	
 	f.logger.Info("Task handlers registered")
 }
 
-// executePlan executes all tasks in a plan
+// startRun marks a plan as running before it is handed to a background run and
+// returns a snapshot of it. From then on only the run touches the plan, so
+// callers read the snapshot instead.
+func startRun(plan *interfaces.Plan) *interfaces.Plan {
+	plan.Status = interfaces.TaskStatusRunning
+	plan.UpdatedAt = time.Now()
+	
+	snapshot := *plan
+	snapshot.Tasks = make([]interfaces.Task, len(plan.Tasks))
+	for i, task := range plan.Tasks {
+		if task.Parameters != nil {
+			params := make(map[string]interface{}, len(task.Parameters))
+			for key, value := range task.Parameters {
+				params[key] = value
+			}
+			task.Parameters = params
+		}
+		task.Dependencies = append([]string(nil), task.Dependencies...)
+		task.Attempts = append([]interfaces.TaskAttempt(nil), task.Attempts...)
+		snapshot.Tasks[i] = task
+	}
+	return &snapshot
+}
+
+// detach returns a context for a plan run in the background. It keeps the
+// values of ctx, such as the usage meter and token budget, but is only
+// cancelled when the framework stops, not when the request that started the
+// run returns.
+func (f *Framework) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(f.runs, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// executePlan executes the tasks of a plan in dependency order
 func (f *Framework) executePlan(ctx context.Context, plan *interfaces.Plan) {
 	f.logger.WithField("plan_id", plan.ID).Info("Starting plan execution")
 	
-	workflowID := "plan:" + plan.ID
+	ctx, cancel := f.detach(ctx)
+	defer cancel()
+	
+	// Enforce the overall plan deadline through the task contexts
+	if f.config.PlanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.config.PlanTimeout)
+		defer cancel()
+	}
+	
+	scheduler := newPlanScheduler(f.runTask, f.config.MaxParallelTasks, f.logger)
+	err := scheduler.Run(ctx, plan)
 	
-	// Execute tasks (simplified - in reality you'd handle dependencies)
-	for _, task := range plan.Tasks {
+	// Revise the unfinished part of the plan and resume until it succeeds or
+	// the replan budget is spent
+	for err != nil && ctx.Err() == nil && plan.Replans < f.maxReplans() {
 		f.logger.WithFields(map[string]interface{}{
 			"plan_id": plan.ID,
-			"task_id": task.ID,
-			"type":    task.Type,
-		}).Info("Executing task")
+			"replan":  plan.Replans + 1,
+			"error":   err.Error(),
+		}).Warn("Plan execution failed, replanning")
 		
-		if err := f.executor.ExecuteTask(ctx, &task); err != nil {
+		if replanErr := f.replan(ctx, plan); replanErr != nil {
 			f.logger.WithFields(map[string]interface{}{
 				"plan_id": plan.ID,
-				"task_id": task.ID,
-				"error":   err.Error(),
-			}).Error("Task execution failed")
-			
-			// Trigger workflow failure
-			f.langGraph.TriggerEvent(ctx, workflowID, "fail", map[string]interface{}{
-				"task_id": task.ID,
-				"error":   err.Error(),
-			})
-			return
+				"error":   replanErr.Error(),
+			}).Error("Failed to replan")
+			break
 		}
+		
+		f.eventBus.Publish(ctx, "plan.replanned", map[string]interface{}{
+			"plan_id":    plan.ID,
+			"replans":    plan.Replans,
+			"error":      err.Error(),
+			"task_count": len(plan.Tasks),
+		})
+		
+		err = scheduler.Run(ctx, plan)
 	}
 	
+	f.finishPlan(ctx, plan, err)
+}
+
+// finishPlan records the outcome of a plan run and fires the matching
+// workflow event
+func (f *Framework) finishPlan(ctx context.Context, plan *interfaces.Plan, err error) {
+	workflowID := "plan:" + plan.ID
+	
+	recordUsage(ctx, plan)
+	plan.UpdatedAt = time.Now()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		plan.Status = interfaces.TaskStatusTimedOut
+		f.logger.WithFields(map[string]interface{}{
+			"plan_id": plan.ID,
+			"timeout": f.config.PlanTimeout.String(),
+		}).Error("Plan execution timed out")
+		
+		// Trigger workflow timeout; the plan context is done, so use a fresh one
+		f.langGraph.TriggerEvent(context.Background(), workflowID, "timeout", map[string]interface{}{
+			"plan_id": plan.ID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		plan.Status = interfaces.TaskStatusFailed
+		f.logger.WithFields(map[string]interface{}{
+			"plan_id": plan.ID,
+			"error":   err.Error(),
+		}).Error("Plan execution failed")
+		
+		// Trigger workflow failure
+		f.langGraph.TriggerEvent(ctx, workflowID, "fail", map[string]interface{}{
+			"plan_id": plan.ID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	
+	plan.Status = interfaces.TaskStatusCompleted
+	
 	// All tasks completed successfully
 	f.langGraph.TriggerEvent(ctx, workflowID, "complete", map[string]interface{}{
 		"plan_id": plan.ID,
@@ -263,6 +562,30 @@ func (f *Framework) executePlan(ctx context.Context, plan *interfaces.Plan) {
 	f.logger.WithField("plan_id", plan.ID).Info("Plan execution completed")
 }
 
+// runTask executes a single task and waits until it reaches a terminal state
+func (f *Framework) runTask(ctx context.Context, task *interfaces.Task) error {
+	f.logger.WithFields(map[string]interface{}{
+		"task_id": task.ID,
+		"type":    task.Type,
+	}).Info("Executing task")
+	
+	ctx = llm.WithUsageTask(ctx, task.ID)
+	ctx = llm.WithReasoningTask(ctx, task.ID)
+	if err := f.executor.ExecuteTask(ctx, task); err != nil {
+		task.Status = interfaces.TaskStatusFailed
+		task.Error = err.Error()
+		task.UpdatedAt = time.Now()
+		return err
+	}
+	
+	// Block until the handler has finished so the plan reflects real outcomes
+	if _, err := f.executor.WaitTask(ctx, task.ID); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
 // startEventMonitoring starts monitoring framework events
 func (f *Framework) startEventMonitoring(ctx context.Context) {
 	// Subscribe to task events
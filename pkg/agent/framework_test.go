@@ -2,6 +2,9 @@ package agent
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -63,6 +66,50 @@ func TestFrameworkLifecycle(t *testing.T) {
 	err = framework.Stop(ctx)
 	assert.NoError(t, err)
 
-	// Note: Start test would require Ollama to be running
-	// In a real test environment, you'd mock the LLM client
+	// Note: Start test would require a browser; LLM calls can be replayed
+	// from a cassette (see TestFrameworkReplaysRecordedLLMCalls)
+}
+
+func TestFrameworkReplaysRecordedLLMCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "llama3", "done": true, "response": ` +
+			`"{\"tasks\": [{\"name\": \"summarize\", \"type\": \"analysis\", \"description\": \"Summarize\", \"parameters\": {\"input\": \"Go is simple\"}}]}"}`))
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	recording, err := NewFramework(&Config{
+		OllamaURL:       server.URL,
+		LogLevel:        "error",
+		MemoryType:      "memory",
+		LLMCassetteMode: "record",
+		LLMCassettePath: path,
+	})
+	require.NoError(t, err)
+	recorded, err := recording.planner.CreatePlan(ctx, "summarize Go")
+	require.NoError(t, err)
+	server.Close()
+
+	// The replay needs no model server
+	replaying, err := NewFramework(&Config{
+		OllamaURL:       server.URL,
+		LogLevel:        "error",
+		MemoryType:      "memory",
+		LLMCassetteMode: "replay",
+		LLMCassettePath: path,
+	})
+	require.NoError(t, err)
+	assert.True(t, replaying.llmClient.IsHealthy(ctx))
+
+	replayed, err := replaying.planner.CreatePlan(ctx, "summarize Go")
+	require.NoError(t, err)
+	require.Len(t, replayed.Tasks, 1)
+	assert.Equal(t, recorded.Tasks[0].Name, replayed.Tasks[0].Name)
+	assert.Equal(t, recorded.Tasks[0].Parameters, replayed.Tasks[0].Parameters)
+
+	_, err = replaying.planner.CreatePlan(ctx, "a goal that was never recorded")
+	assert.ErrorContains(t, err, "no recorded generate response")
+
+	_, err = NewFramework(&Config{LLMCassetteMode: "replay"})
+	assert.EqualError(t, err, `LLM cassette mode "replay" requires a cassette path`)
 }
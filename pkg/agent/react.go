@@ -0,0 +1,404 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/extract"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/planner"
+	"github.com/ai-agent-framework/pkg/schema"
+	"github.com/google/uuid"
+)
+
+// defaultMaxAgentSteps is used when Config.MaxAgentSteps is not set
+const defaultMaxAgentSteps = 15
+
+// maxObservationChars bounds how much of a task's result is shown to the LLM
+const maxObservationChars = 3000
+
+// maxPageTextChars bounds the page text observed after a browser task
+const maxPageTextChars = 1500
+
+// Actions the LLM can choose in the iterative loop
+const (
+	agentActionTask   = "task"
+	agentActionFinish = "finish"
+)
+
+// agentDecision is the next step chosen by the LLM
+type agentDecision struct {
+	Thought string `json:"thought"`
+	Action  string `json:"action"`
+	Task    *struct {
+		Type        string                 `json:"type"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"task"`
+	Answer string `json:"answer"`
+}
+
+// agentStep records one iteration of the loop for the next prompt
+type agentStep struct {
+	Thought     string
+	Task        *interfaces.Task
+	Observation string
+}
+
+// maxAgentSteps returns how many steps an iterative goal may take
+func (f *Framework) maxAgentSteps() int {
+	if f.config.MaxAgentSteps > 0 {
+		return f.config.MaxAgentSteps
+	}
+	return defaultMaxAgentSteps
+}
+
+// ExecuteGoalIterative pursues a goal without an upfront plan. The LLM picks
+// one task at a time from the registered handlers, observes its outcome and
+// decides the next step until it declares the goal done or the step budget
+// is spent. The executed steps are recorded as the tasks of the plan stored in
+// memory; the returned plan is a snapshot taken as the run starts.
+func (f *Framework) ExecuteGoalIterative(ctx context.Context, goal string) (*interfaces.Plan, error) {
+	f.logger.WithField("goal", goal).Info("Executing goal iteratively")
+
+	if !f.isRunning {
+		return nil, fmt.Errorf("framework is not running")
+	}
+
+	ctx = f.meterPlan(ctx)
+
+	now := time.Now()
+	plan := &interfaces.Plan{
+		ID:        uuid.New().String(),
+		Goal:      goal,
+		Tasks:     []interfaces.Task{},
+		Status:    interfaces.TaskStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := f.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
+		f.logger.WithField("error", err).Warn("Failed to store plan in memory")
+	}
+
+	f.startWorkflow(ctx, plan)
+
+	snapshot := startRun(plan)
+	go f.runIterative(ctx, plan)
+
+	return snapshot, nil
+}
+
+// runIterative drives the observe-think-act loop of a plan and records its outcome
+func (f *Framework) runIterative(ctx context.Context, plan *interfaces.Plan) {
+	f.logger.WithField("plan_id", plan.ID).Info("Starting iterative execution")
+
+	ctx, cancel := f.detach(ctx)
+	defer cancel()
+
+	if f.config.PlanTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.config.PlanTimeout)
+		defer cancel()
+	}
+
+	err := f.iterate(ctx, plan)
+	f.finishPlan(ctx, plan, err)
+
+	if storeErr := f.memory.Store(context.Background(), "plan:"+plan.ID, plan); storeErr != nil {
+		f.logger.WithField("error", storeErr).Warn("Failed to store plan in memory")
+	}
+}
+
+// iterate runs steps until the LLM finishes the goal, the step budget is
+// spent or the context ends
+func (f *Framework) iterate(ctx context.Context, plan *interfaces.Plan) error {
+	maxSteps := f.maxAgentSteps()
+	var steps []agentStep
+
+	for number := 1; number <= maxSteps; number++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		prompt := buildAgentPrompt(plan.Goal, f.executor.DescribeHandlers(), steps, maxSteps-number+1)
+		decision, err := f.decideNextStep(ctx, prompt)
+		if err != nil {
+			var invalid *invalidDecisionError
+			if !errors.As(err, &invalid) {
+				return err
+			}
+			// Show the mistake to the LLM; it costs a step like any other
+			steps = append(steps, agentStep{Observation: "Your response could not be used: " + invalid.Error()})
+			continue
+		}
+
+		if decision.Action == agentActionFinish {
+			plan.Answer = decision.Answer
+			f.logger.WithFields(map[string]interface{}{
+				"plan_id": plan.ID,
+				"steps":   len(plan.Tasks),
+			}).Info("Agent finished goal")
+			return nil
+		}
+
+		now := time.Now()
+		task := &interfaces.Task{
+			ID:           uuid.New().String(),
+			Name:         fmt.Sprintf("step_%d", number),
+			Type:         decision.Task.Type,
+			Description:  decision.Task.Description,
+			Parameters:   decision.Task.Parameters,
+			Status:       interfaces.TaskStatusPending,
+			Dependencies: completedTaskIDs(plan.Tasks),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if task.Parameters == nil {
+			task.Parameters = map[string]interface{}{}
+		}
+
+		runErr := f.runTask(ctx, task)
+		if runErr != nil && task.Status != interfaces.TaskStatusFailed && task.Status != interfaces.TaskStatusTimedOut {
+			// The task never reached a terminal state, e.g. the plan deadline passed
+			task.Status = interfaces.TaskStatusFailed
+			task.Error = runErr.Error()
+		}
+
+		plan.Tasks = append(plan.Tasks, *task)
+		plan.UpdatedAt = time.Now()
+		recordUsage(ctx, plan)
+
+		steps = append(steps, agentStep{
+			Thought:     decision.Thought,
+			Task:        task,
+			Observation: f.observe(ctx, task),
+		})
+
+		f.eventBus.Publish(ctx, "plan.step", map[string]interface{}{
+			"plan_id": plan.ID,
+			"step":    number,
+			"task_id": task.ID,
+			"type":    task.Type,
+			"status":  string(task.Status),
+			"thought": decision.Thought,
+		})
+
+		if err := f.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
+			f.logger.WithField("error", err).Warn("Failed to store plan in memory")
+		}
+	}
+
+	return fmt.Errorf("step budget of %d exhausted before the goal was reached", maxSteps)
+}
+
+// invalidDecisionError reports an LLM response that is not a usable decision
+type invalidDecisionError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e *invalidDecisionError) Error() string {
+	return e.err.Error()
+}
+
+// decideNextStep asks the LLM for the next step, constraining the output to
+// the decision schema
+func (f *Framework) decideNextStep(ctx context.Context, prompt string) (*agentDecision, error) {
+	taskTypes := make([]string, 0)
+	for _, description := range f.executor.DescribeHandlers() {
+		taskTypes = append(taskTypes, description.TaskType)
+	}
+
+	llmReq := interfaces.LLMRequest{
+		Prompt: prompt,
+		Format: decisionSchema(taskTypes),
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": 0.2,
+		},
+		Stage: llm.StageElementSelection,
+	}
+
+	// The next step blocks the goal, so it is queued before other requests
+	ctx = llm.WithPriority(ctx, llm.PriorityHigh)
+
+	resp, err := f.llmClient.Generate(ctx, llmReq)
+	if err != nil && llm.IsRejected(err) {
+		// Servers without structured output support reject the format
+		f.logger.WithField("error", err).Warn("Structured step request failed, retrying without an output format")
+		llmReq.Format = nil
+		resp, err = f.llmClient.Generate(ctx, llmReq)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide next step: %w", err)
+	}
+
+	decision, err := parseDecision(resp.Response)
+	if err != nil {
+		return nil, &invalidDecisionError{err: err}
+	}
+	return decision, nil
+}
+
+// decisionSchema returns the JSON schema step decisions must follow. Task
+// types are only constrained when taskTypes is given.
+func decisionSchema(taskTypes []string) map[string]interface{} {
+	typeSchema := map[string]interface{}{"type": "string"}
+	if len(taskTypes) > 0 {
+		types := make([]interface{}, len(taskTypes))
+		for i, taskType := range taskTypes {
+			types[i] = taskType
+		}
+		typeSchema["enum"] = types
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"thought": map[string]interface{}{"type": "string"},
+			"action":  map[string]interface{}{"type": "string", "enum": []interface{}{agentActionTask, agentActionFinish}},
+			"task": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"type":        typeSchema,
+					"description": map[string]interface{}{"type": "string", "minLength": 1},
+					"parameters":  map[string]interface{}{"type": "object"},
+				},
+				"required": []interface{}{"type", "description"},
+			},
+			"answer": map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"action"},
+	}
+}
+
+// parseDecision extracts the decision object from an LLM response and checks
+// that it names a task or an answer
+func parseDecision(response string) (*agentDecision, error) {
+	encoded, err := llm.ExtractJSONObject(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(encoded), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if err := schema.Validate(decisionSchema(nil), document); err != nil {
+		return nil, fmt.Errorf("decision does not match the expected structure: %w", err)
+	}
+
+	var decision agentDecision
+	if err := json.Unmarshal([]byte(encoded), &decision); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	switch decision.Action {
+	case agentActionTask:
+		if decision.Task == nil {
+			return nil, fmt.Errorf("task: is required when the action is %q", agentActionTask)
+		}
+	case agentActionFinish:
+		if strings.TrimSpace(decision.Answer) == "" {
+			return nil, fmt.Errorf("answer: is required when the action is %q", agentActionFinish)
+		}
+	}
+
+	return &decision, nil
+}
+
+// observe describes the outcome of a step for the LLM. Browser steps also
+// show the text of the current page so the LLM sees what it navigated to.
+func (f *Framework) observe(ctx context.Context, task *interfaces.Task) string {
+	var builder strings.Builder
+
+	if task.Status == interfaces.TaskStatusCompleted {
+		result := "null"
+		if encoded, err := json.Marshal(task.Result); err == nil {
+			result = string(encoded)
+		}
+		builder.WriteString("Task completed. Result: " + truncate(result, maxObservationChars))
+	} else {
+		builder.WriteString(fmt.Sprintf("Task %s. Error: %s", task.Status, task.Error))
+	}
+
+	if task.Type == "browser" && f.browserAgent != nil {
+		if content, err := f.browserAgent.GetPageContent(ctx); err == nil {
+			if document, err := extract.Parse(content); err == nil {
+				if text := strings.TrimSpace(document.Text()); text != "" {
+					builder.WriteString("\nPage text: " + truncate(text, maxPageTextChars))
+				}
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// buildAgentPrompt creates the prompt asking for the next step towards a goal
+func buildAgentPrompt(goal string, handlers []interfaces.HandlerDescription, steps []agentStep, remaining int) string {
+	history := "No steps have been taken yet."
+	if len(steps) > 0 {
+		var builder strings.Builder
+		builder.WriteString("Steps so far:")
+		for i, step := range steps {
+			builder.WriteString(fmt.Sprintf("\n\nStep %d", i+1))
+			if step.Thought != "" {
+				builder.WriteString("\nThought: " + step.Thought)
+			}
+			if step.Task != nil {
+				parameters, _ := json.Marshal(step.Task.Parameters)
+				builder.WriteString(fmt.Sprintf("\nAction: %s task %q: %s\nParameters: %s",
+					step.Task.Type, step.Task.Name, step.Task.Description, parameters))
+			}
+			builder.WriteString("\nObservation: " + step.Observation)
+		}
+		history = builder.String()
+	}
+
+	return fmt.Sprintf(`You are an autonomous agent working towards a goal one step at a time. At each step, either run one task using the task types below, after which you are shown its outcome, or finish once the goal is achieved.
+
+Goal: %s
+
+%s
+
+The result of an earlier task can be passed to a parameter with an expression such as "{{ tasks.step_1.result }}".
+
+%s
+
+Respond with only a JSON object in one of these forms:
+{"thought": "why this step is needed", "action": "task", "task": {"type": "task type", "description": "what the task does", "parameters": {}}}
+{"thought": "why the goal is achieved", "action": "finish", "answer": "the final answer to the goal"}
+
+Steps left: %d
+
+Response:`, goal, planner.DescribeTaskTypes(handlers), history, remaining)
+}
+
+// completedTaskIDs returns the IDs of the completed tasks, which later steps
+// may reference in their parameters
+func completedTaskIDs(tasks []interfaces.Task) []string {
+	var ids []string
+	for _, task := range tasks {
+		if task.Status == interfaces.TaskStatusCompleted {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+// truncate shortens s to at most limit bytes without splitting a rune,
+// marking the cut
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return executor.TruncateUTF8(s, limit) + "...(truncated)"
+}
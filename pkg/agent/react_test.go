@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedLLMClient returns canned responses in order, repeating the last one
+type scriptedLLMClient struct {
+	mu        sync.Mutex
+	responses []string
+	prompts   []string
+}
+
+func (c *scriptedLLMClient) Generate(ctx context.Context, req interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prompts = append(c.prompts, req.Prompt)
+	index := len(c.prompts) - 1
+	if index >= len(c.responses) {
+		index = len(c.responses) - 1
+	}
+	return &interfaces.LLMResponse{Response: c.responses[index], Done: true}, nil
+}
+
+func (c *scriptedLLMClient) Chat(ctx context.Context, req interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *scriptedLLMClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *scriptedLLMClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+func TestIterateRunsStepsUntilFinished(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{
+		`{"thought": "fetch the page first", "action": "task", "task": {"type": "test", "description": "fetch", "parameters": {"url": "https://example.com"}}}`,
+		"<think>the page is fetched</think>\n" +
+			`{"thought": "parse it", "action": "task", "task": {"type": "test", "description": "parse", "parameters": {"input": "{{ tasks.step_1.result }}"}}}`,
+		`{"thought": "done", "action": "finish", "answer": "parsed page"}`,
+	}}
+
+	f := newReplanTestFramework(nil, &Config{})
+	f.llmClient = client
+
+	var inputs []interface{}
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		if task.Description == "fetch" {
+			task.Result = "page"
+			return nil
+		}
+		inputs = append(inputs, task.Parameters["input"])
+		task.Result = "parsed"
+		return nil
+	}))
+
+	plan := &interfaces.Plan{ID: "plan-1", Goal: "parse example.com"}
+	f.runIterative(context.Background(), plan)
+
+	assert.Equal(t, interfaces.TaskStatusCompleted, plan.Status)
+	assert.Equal(t, "parsed page", plan.Answer)
+
+	require.Len(t, plan.Tasks, 2)
+	assert.Equal(t, "step_1", plan.Tasks[0].Name)
+	assert.Equal(t, "step_2", plan.Tasks[1].Name)
+	assert.Equal(t, []string{plan.Tasks[0].ID}, plan.Tasks[1].Dependencies)
+	assert.Equal(t, []interface{}{"page"}, inputs, "results of earlier steps must be usable in parameters")
+
+	require.Len(t, client.prompts, 3)
+	assert.Contains(t, client.prompts[0], "Goal: parse example.com")
+	assert.Contains(t, client.prompts[0], "No steps have been taken yet.")
+	assert.Contains(t, client.prompts[1], "Thought: fetch the page first")
+	assert.Contains(t, client.prompts[1], `Observation: Task completed. Result: "page"`)
+	assert.Contains(t, client.prompts[2], `Observation: Task completed. Result: "parsed"`)
+}
+
+func TestIterateStopsAtStepBudget(t *testing.T) {
+	client := &scriptedLLMClient{responses: []string{
+		"I am not sure what to do",
+		`{"thought": "try it", "action": "task", "task": {"type": "test", "description": "attempt"}}`,
+	}}
+
+	f := newReplanTestFramework(nil, &Config{MaxAgentSteps: 3})
+	f.llmClient = client
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		return executor.Permanent(fmt.Errorf("site unavailable"))
+	}))
+
+	plan := &interfaces.Plan{ID: "plan-1", Goal: "attempt"}
+	f.runIterative(context.Background(), plan)
+
+	assert.Equal(t, interfaces.TaskStatusFailed, plan.Status)
+	require.Len(t, plan.Tasks, 2)
+	assert.Equal(t, "step_2", plan.Tasks[0].Name)
+	assert.Equal(t, interfaces.TaskStatusFailed, plan.Tasks[0].Status)
+	assert.Empty(t, plan.Tasks[1].Dependencies, "failed steps are not dependencies")
+
+	require.Len(t, client.prompts, 3)
+	assert.Contains(t, client.prompts[1], "Your response could not be used: no JSON object found in response")
+	assert.Contains(t, client.prompts[2], "site unavailable")
+	assert.Contains(t, client.prompts[2], "Steps left: 1")
+}
+
+func TestParseDecisionRequiresTaskOrAnswer(t *testing.T) {
+	_, err := parseDecision(`{"action": "finish"}`)
+	assert.EqualError(t, err, `answer: is required when the action is "finish"`)
+
+	_, err = parseDecision(`{"action": "task"}`)
+	assert.EqualError(t, err, `task: is required when the action is "task"`)
+
+	_, err = parseDecision(`{"action": "wait"}`)
+	assert.ErrorContains(t, err, "action: must be one of")
+
+	decision, err := parseDecision("```json\n{\"action\": \"task\", \"task\": {\"type\": \"browser\", \"description\": \"open\"}}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, "browser", decision.Task.Type)
+}
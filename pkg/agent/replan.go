@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// defaultMaxReplans is used when replanning is enabled without Config.MaxReplans
+const defaultMaxReplans = 2
+
+// maxReplanResultChars bounds how much of each completed task's result is fed back to the planner
+const maxReplanResultChars = 2000
+
+// maxReplans returns how often a failed plan may be revised
+func (f *Framework) maxReplans() int {
+	if !f.config.ReplanOnFailure {
+		return 0
+	}
+	if f.config.MaxReplans > 0 {
+		return f.config.MaxReplans
+	}
+	return defaultMaxReplans
+}
+
+// replan asks the planner to revise the unfinished part of a failed plan and
+// runs the revision as the planner recorded it. The planner carries completed
+// tasks the revision keeps unchanged over with their IDs and results, so they
+// do not run again.
+func (f *Framework) replan(ctx context.Context, plan *interfaces.Plan) error {
+	previous := append([]interfaces.Task(nil), plan.Tasks...)
+
+	revised, err := f.planner.UpdatePlan(ctx, plan.ID, buildReplanFeedback(previous))
+	if err != nil {
+		// The planner may have replaced the tasks before failing
+		plan.Tasks = previous
+		return err
+	}
+
+	plan.Tasks = revised.Tasks
+	plan.Replans++
+	plan.UpdatedAt = time.Now()
+	recordUsage(ctx, plan)
+
+	if err := f.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
+		f.logger.WithField("error", err).Warn("Failed to store revised plan in memory")
+	}
+
+	return nil
+}
+
+// buildReplanFeedback describes the outcome of a failed plan run for the planner
+func buildReplanFeedback(tasks []interfaces.Task) string {
+	var completed, failed, skipped []string
+
+	for _, task := range tasks {
+		label := task.Name
+		if label == "" {
+			label = task.ID
+		}
+
+		switch task.Status {
+		case interfaces.TaskStatusCompleted:
+			result := ""
+			if task.Result != nil {
+				encoded, err := json.Marshal(task.Result)
+				if err == nil {
+					result = string(encoded)
+				}
+				result = truncate(result, maxReplanResultChars)
+			}
+			completed = append(completed, fmt.Sprintf("- %s (%s): %s\n  result: %s", label, task.Type, task.Description, result))
+		case interfaces.TaskStatusSkipped:
+			skipped = append(skipped, fmt.Sprintf("- %s (%s): %s", label, task.Type, task.Description))
+		default:
+			failed = append(failed, fmt.Sprintf("- %s (%s): %s\n  status: %s, attempts: %d\n  error: %s", label, task.Type, task.Description, task.Status, len(task.Attempts), task.Error))
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Execution of the plan failed. Revise the tasks that have not completed so the goal can still be reached, for example by fixing parameters or choosing a different approach.\n")
+
+	builder.WriteString("\nFailed tasks:\n")
+	builder.WriteString(strings.Join(failed, "\n"))
+
+	if len(skipped) > 0 {
+		builder.WriteString("\n\nTasks skipped because an upstream task failed:\n")
+		builder.WriteString(strings.Join(skipped, "\n"))
+	}
+
+	if len(completed) > 0 {
+		builder.WriteString("\n\nCompleted tasks (include them unchanged under the same names; they will not run again, and new tasks may depend on them):\n")
+		builder.WriteString(strings.Join(completed, "\n"))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,315 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ai-agent-framework/pkg/eventbus"
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/langgraph"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/ai-agent-framework/pkg/planner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPlanner revises plans with a fixed function and records the feedback
+type stubPlanner struct {
+	revise   func(plan *interfaces.Plan) []interfaces.Task
+	plans    map[string]*interfaces.Plan
+	feedback []string
+}
+
+func (p *stubPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.Plan, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (p *stubPlanner) UpdatePlan(ctx context.Context, planID string, feedback string) (*interfaces.Plan, error) {
+	p.feedback = append(p.feedback, feedback)
+	plan := p.plans[planID]
+	plan.Tasks = p.revise(plan)
+	return plan, nil
+}
+
+func (p *stubPlanner) GetPlan(ctx context.Context, planID string) (*interfaces.Plan, error) {
+	return p.plans[planID], nil
+}
+
+func (p *stubPlanner) GetPlanHistory(ctx context.Context, planID string) ([]interfaces.PlanRevision, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (p *stubPlanner) GetPlanRevision(ctx context.Context, planID string, revision int) (*interfaces.PlanRevision, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// newReplanTestFramework wires a framework with real execution components and
+// the given planner
+func newReplanTestFramework(planner interfaces.Planner, config *Config) *Framework {
+	log := logger.NewLogrusLogger("error")
+	store := memory.NewInMemoryStore(log)
+	bus := eventbus.NewInMemoryEventBus(log)
+
+	return &Framework{
+		planner:   planner,
+		executor:  executor.NewTaskExecutor(store, bus, log),
+		memory:    store,
+		langGraph: langgraph.NewLangGraphEngine(store, log),
+		eventBus:  bus,
+		logger:    log,
+		config:    config,
+		runs:      context.Background(),
+	}
+}
+
+func TestExecutePlanReplansFailedTasks(t *testing.T) {
+	plan := &interfaces.Plan{
+		ID: "plan-1",
+		Tasks: []interfaces.Task{
+			{ID: "fetch-1", Name: "fetch", Type: "test", Status: interfaces.TaskStatusPending},
+			{ID: "parse-1", Name: "parse", Type: "test", Status: interfaces.TaskStatusPending, Dependencies: []string{"fetch-1"},
+				Parameters: map[string]interface{}{"mode": "strict"}},
+		},
+	}
+
+	planner := &stubPlanner{
+		plans: map[string]*interfaces.Plan{plan.ID: plan},
+		revise: func(plan *interfaces.Plan) []interfaces.Task {
+			// Like the task planner, carry the unchanged completed task over
+			return []interfaces.Task{
+				plan.Tasks[0],
+				{ID: "parse-2", Name: "parse", Type: "test", Status: interfaces.TaskStatusPending, Dependencies: []string{"fetch-1"},
+					Parameters: map[string]interface{}{"mode": "lenient"}},
+			}
+		},
+	}
+
+	f := newReplanTestFramework(planner, &Config{ReplanOnFailure: true, MaxReplans: 1})
+
+	var fetches int32
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		if task.Name == "fetch" {
+			atomic.AddInt32(&fetches, 1)
+			task.Result = "page"
+			return nil
+		}
+		if task.Parameters["mode"] == "strict" {
+			return executor.Permanent(fmt.Errorf("strict parsing failed"))
+		}
+		return nil
+	}))
+
+	f.executePlan(context.Background(), plan)
+
+	assert.Equal(t, interfaces.TaskStatusCompleted, plan.Status)
+	assert.Equal(t, 1, plan.Replans)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches), "completed tasks must not run again")
+
+	require.Len(t, plan.Tasks, 2)
+	assert.Equal(t, "fetch-1", plan.Tasks[0].ID)
+	assert.Equal(t, "parse-2", plan.Tasks[1].ID)
+	assert.Equal(t, []string{"fetch-1"}, plan.Tasks[1].Dependencies)
+
+	require.Len(t, planner.feedback, 1)
+	assert.Contains(t, planner.feedback[0], "strict parsing failed")
+	assert.Contains(t, planner.feedback[0], `result: "page"`)
+}
+
+func TestExecutePlanStopsAfterMaxReplans(t *testing.T) {
+	plan := &interfaces.Plan{
+		ID:    "plan-1",
+		Tasks: []interfaces.Task{{ID: "t-0", Name: "flaky", Type: "test", Status: interfaces.TaskStatusPending}},
+	}
+
+	revisions := 0
+	planner := &stubPlanner{
+		plans: map[string]*interfaces.Plan{plan.ID: plan},
+		revise: func(plan *interfaces.Plan) []interfaces.Task {
+			revisions++
+			return []interfaces.Task{{ID: fmt.Sprintf("t-%d", revisions), Name: "flaky", Type: "test", Status: interfaces.TaskStatusPending}}
+		},
+	}
+
+	f := newReplanTestFramework(planner, &Config{ReplanOnFailure: true, MaxReplans: 2})
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		return executor.Permanent(fmt.Errorf("always fails"))
+	}))
+
+	f.executePlan(context.Background(), plan)
+
+	assert.Equal(t, interfaces.TaskStatusFailed, plan.Status)
+	assert.Equal(t, 2, plan.Replans)
+	assert.Equal(t, 2, revisions)
+}
+
+// taskFunc adapts a function to the TaskHandler interface
+type taskFunc func(ctx context.Context, task *interfaces.Task) error
+
+func (h taskFunc) Handle(ctx context.Context, task *interfaces.Task) error {
+	return h(ctx, task)
+}
+
+func (h taskFunc) CanHandle(taskType string) bool {
+	return true
+}
+
+func TestExecutePlanOutlivesTheStartingRequest(t *testing.T) {
+	plan := &interfaces.Plan{
+		ID:    "plan-1",
+		Tasks: []interfaces.Task{{ID: "t-1", Name: "fetch", Type: "test", Status: interfaces.TaskStatusPending}},
+	}
+	f := newReplanTestFramework(&stubPlanner{}, &Config{})
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		}
+	}))
+
+	// The request that started the plan has already returned
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f.executePlan(ctx, plan)
+	assert.Equal(t, interfaces.TaskStatusCompleted, plan.Status)
+
+	// Stopping the framework cancels the run
+	runs, stopRuns := context.WithCancel(context.Background())
+	f.runs = runs
+	stopRuns()
+	plan.Tasks[0].Status = interfaces.TaskStatusPending
+	f.executePlan(context.Background(), plan)
+	assert.Equal(t, interfaces.TaskStatusFailed, plan.Status)
+}
+
+func TestReplanRunsTheRecordedRevision(t *testing.T) {
+	ctx := context.Background()
+	f := newReplanTestFramework(nil, &Config{ReplanOnFailure: true})
+	taskPlanner := planner.NewTaskPlanner(&scriptedLLMClient{responses: []string{`{"tasks": [
+		{"name": "fetch", "type": "api", "description": "Fetch the page", "parameters": {"url": "https://example.com/print"}},
+		{"name": "parse", "type": "api", "description": "Parse leniently", "parameters": {"mode": "lenient"}, "dependencies": ["fetch"]}
+	]}`}}, f.memory, f.logger)
+	f.planner = taskPlanner
+
+	plan := &interfaces.Plan{
+		ID:   "plan-1",
+		Goal: "parse the page",
+		Tasks: []interfaces.Task{
+			{ID: "fetch-1", Name: "fetch", Type: "api", Description: "Fetch the page", Status: interfaces.TaskStatusCompleted, Result: "page"},
+			{ID: "parse-1", Name: "parse", Type: "api", Description: "Parse strictly", Status: interfaces.TaskStatusFailed,
+				Dependencies: []string{"fetch-1"}, Parameters: map[string]interface{}{"mode": "strict"}},
+		},
+	}
+	require.NoError(t, f.memory.Store(ctx, "plan:"+plan.ID, plan))
+
+	require.NoError(t, f.replan(ctx, plan))
+
+	revision, err := taskPlanner.GetPlanRevision(ctx, plan.ID, plan.Revision)
+	require.NoError(t, err)
+	require.Len(t, plan.Tasks, 2)
+	require.Len(t, revision.Tasks, 2)
+	for i := range plan.Tasks {
+		assert.Equal(t, revision.Tasks[i].ID, plan.Tasks[i].ID)
+		assert.Equal(t, revision.Tasks[i].Status, plan.Tasks[i].Status)
+		assert.Equal(t, revision.Tasks[i].Dependencies, plan.Tasks[i].Dependencies)
+	}
+	// The revision changed the completed task, so it runs again
+	assert.NotEqual(t, "fetch-1", plan.Tasks[0].ID)
+	assert.Equal(t, interfaces.TaskStatusPending, plan.Tasks[0].Status)
+	assert.Equal(t, "lenient", plan.Tasks[1].Parameters["mode"])
+}
+
+func TestExecutePlanReturnsASnapshot(t *testing.T) {
+	plan := &interfaces.Plan{
+		ID:    "plan-1",
+		Tasks: []interfaces.Task{{ID: "t-1", Name: "fetch", Type: "test", Status: interfaces.TaskStatusPending}},
+	}
+	f := newReplanTestFramework(&stubPlanner{}, &Config{})
+	f.isRunning = true
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		task.Result = "page"
+		return nil
+	}))
+
+	snapshot, err := f.ExecutePlan(context.Background(), plan)
+	require.NoError(t, err)
+
+	// The run updates the plan while the snapshot is read
+	assert.Equal(t, interfaces.TaskStatusRunning, snapshot.Status)
+	assert.Equal(t, interfaces.TaskStatusPending, snapshot.Tasks[0].Status)
+
+	assert.Eventually(t, func() bool {
+		state, err := f.langGraph.GetCurrentState(context.Background(), "plan:"+plan.ID)
+		return err == nil && state == "completed"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, interfaces.TaskStatusCompleted, plan.Status)
+	assert.Equal(t, interfaces.TaskStatusRunning, snapshot.Status)
+}
+
+func TestBuildReplanFeedbackKeepsResultRunesWhole(t *testing.T) {
+	// The encoded result opens with a quote, so é straddles the limit
+	result := strings.Repeat("a", maxReplanResultChars-2) + "é"
+	feedback := buildReplanFeedback([]interfaces.Task{
+		{Name: "fetch", Type: "api", Status: interfaces.TaskStatusCompleted, Result: result},
+	})
+
+	assert.True(t, utf8.ValidString(feedback))
+	assert.Contains(t, feedback, strings.Repeat("a", maxReplanResultChars-2)+"...(truncated)")
+}
+
+func TestReplanReplaysFromCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	log := logger.NewLogrusLogger("error")
+
+	run := func(client interfaces.LLMClient) *interfaces.Plan {
+		f := newReplanTestFramework(nil, &Config{ReplanOnFailure: true, MaxReplans: 1})
+		taskPlanner := planner.NewTaskPlanner(client, f.memory, log)
+		taskPlanner.SetHandlerCatalog(f.executor)
+		f.planner = taskPlanner
+		f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+			if task.Parameters["mode"] == "strict" {
+				return executor.Permanent(fmt.Errorf("strict parsing failed"))
+			}
+			task.Result = task.Name
+			return nil
+		}))
+
+		plan, err := taskPlanner.CreatePlan(context.Background(), "parse the page")
+		require.NoError(t, err)
+		f.executePlan(context.Background(), plan)
+		return plan
+	}
+
+	scripted := &scriptedLLMClient{responses: []string{
+		`{"tasks": [{"name": "fetch", "type": "test", "description": "Fetch"},
+  {"name": "parse", "type": "test", "description": "Parse", "parameters": {"mode": "strict"}, "dependencies": ["fetch"]}]}`,
+		`{"tasks": [{"name": "fetch", "type": "test", "description": "Fetch"},
+  {"name": "parse", "type": "test", "description": "Parse", "parameters": {"mode": "lenient"}, "dependencies": ["fetch"]}]}`,
+	}}
+	recording, err := llm.NewRecordingClient(scripted, path, log)
+	require.NoError(t, err)
+	recorded := run(recording)
+	require.Len(t, scripted.prompts, 2)
+
+	// The revised plan has new task IDs, which must not reach the prompts
+	replaying, err := llm.NewReplayClient(path, log)
+	require.NoError(t, err)
+	replayed := run(replaying)
+
+	for _, plan := range []*interfaces.Plan{recorded, replayed} {
+		assert.Equal(t, interfaces.TaskStatusCompleted, plan.Status)
+		assert.Equal(t, 1, plan.Replans)
+		require.Len(t, plan.Tasks, 2)
+		assert.Equal(t, "lenient", plan.Tasks[1].Parameters["mode"])
+	}
+}
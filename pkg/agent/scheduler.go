@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// defaultMaxParallelTasks is used when Config.MaxParallelTasks is not set
+const defaultMaxParallelTasks = 4
+
+// taskRunner runs a single task to completion and reports its outcome
+type taskRunner func(ctx context.Context, task *interfaces.Task) error
+
+// planScheduler executes the tasks of a plan as a dependency graph
+type planScheduler struct {
+	runTask     taskRunner
+	maxParallel int
+	logger      interfaces.Logger
+}
+
+// taskOutcome is sent back to the scheduler loop when a task finishes
+type taskOutcome struct {
+	index int
+	err   error
+}
+
+// newPlanScheduler creates a scheduler that runs at most maxParallel tasks at once
+func newPlanScheduler(runTask taskRunner, maxParallel int, logger interfaces.Logger) *planScheduler {
+	if maxParallel <= 0 {
+		maxParallel = defaultMaxParallelTasks
+	}
+
+	return &planScheduler{
+		runTask:     runTask,
+		maxParallel: maxParallel,
+		logger:      logger,
+	}
+}
+
+// Run starts every task whose dependencies have completed, running independent
+// branches concurrently. When a task fails, all tasks depending on it (directly
+// or transitively) are skipped while unrelated branches keep running. Tasks
+// that are already completed, such as those kept across a replan, are not run
+// again.
+func (s *planScheduler) Run(ctx context.Context, plan *interfaces.Plan) error {
+	tasks := plan.Tasks
+
+	index := make(map[string]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+
+	// Build the dependency graph, ignoring references to unknown tasks
+	dependents := make([][]int, len(tasks))
+	remaining := make([]int, len(tasks))
+	for i := range tasks {
+		for _, depID := range tasks[i].Dependencies {
+			j, ok := index[depID]
+			if !ok || j == i {
+				s.logger.WithFields(map[string]interface{}{
+					"plan_id":    plan.ID,
+					"task_id":    tasks[i].ID,
+					"dependency": depID,
+				}).Warn("Ignoring unknown task dependency")
+				continue
+			}
+			dependents[j] = append(dependents[j], i)
+			remaining[i]++
+		}
+	}
+
+	outcomes := make(chan taskOutcome, len(tasks))
+	done := make([]bool, len(tasks))
+	running := 0
+	finished := 0
+	var failed []string
+
+	for i := range tasks {
+		if tasks[i].Status == interfaces.TaskStatusCompleted {
+			done[i] = true
+			finished++
+			for _, dep := range dependents[i] {
+				remaining[dep]--
+			}
+		}
+	}
+
+	ready := make([]int, 0, len(tasks))
+	for i := range tasks {
+		if remaining[i] == 0 && !done[i] {
+			ready = append(ready, i)
+		}
+	}
+
+	for finished < len(tasks) {
+		// Launch as many ready tasks as the parallelism limit allows
+		for len(ready) > 0 && running < s.maxParallel && ctx.Err() == nil {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func(i int) {
+				outcomes <- taskOutcome{index: i, err: s.runTask(ctx, &tasks[i])}
+			}(i)
+		}
+
+		if running == 0 {
+			// Nothing is running and nothing can start: either the context was
+			// cancelled or the remaining tasks form a dependency cycle
+			reason := "dependency cycle"
+			if ctx.Err() != nil {
+				reason = ctx.Err().Error()
+			}
+			for i := range tasks {
+				if !done[i] {
+					s.skipTask(&tasks[i], reason)
+					done[i] = true
+				}
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("plan execution interrupted: %w", ctx.Err())
+			}
+			return fmt.Errorf("plan %s has unresolvable dependencies", plan.ID)
+		}
+
+		outcome := <-outcomes
+		running--
+		finished++
+		done[outcome.index] = true
+		task := &tasks[outcome.index]
+
+		if outcome.err != nil {
+			failed = append(failed, task.ID)
+			finished += s.skipDependents(tasks, dependents, done, outcome.index)
+			continue
+		}
+
+		for _, dep := range dependents[outcome.index] {
+			remaining[dep]--
+			if remaining[dep] == 0 && !done[dep] {
+				ready = append(ready, dep)
+			}
+		}
+		sort.Ints(ready)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d task(s) failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// skipDependents marks every transitive dependent of the task at index as
+// skipped and returns the number of tasks it marked
+func (s *planScheduler) skipDependents(tasks []interfaces.Task, dependents [][]int, done []bool, index int) int {
+	skipped := 0
+	queue := append([]int(nil), dependents[index]...)
+
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if done[i] {
+			continue
+		}
+
+		done[i] = true
+		skipped++
+		s.skipTask(&tasks[i], fmt.Sprintf("upstream task %s failed", tasks[index].ID))
+		queue = append(queue, dependents[i]...)
+	}
+
+	return skipped
+}
+
+// skipTask marks a task that will never run
+func (s *planScheduler) skipTask(task *interfaces.Task, reason string) {
+	task.Status = interfaces.TaskStatusSkipped
+	task.Error = reason
+	task.UpdatedAt = time.Now()
+
+	s.logger.WithFields(map[string]interface{}{
+		"task_id": task.ID,
+		"reason":  reason,
+	}).Warn("Skipping task")
+}
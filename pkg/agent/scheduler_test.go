@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPlan(deps map[string][]string, order ...string) *interfaces.Plan {
+	plan := &interfaces.Plan{ID: "plan-1"}
+	for _, id := range order {
+		plan.Tasks = append(plan.Tasks, interfaces.Task{
+			ID:           id,
+			Type:         "script",
+			Status:       interfaces.TaskStatusPending,
+			Dependencies: deps[id],
+		})
+	}
+	return plan
+}
+
+func TestPlanSchedulerRespectsDependencies(t *testing.T) {
+	plan := newTestPlan(map[string][]string{
+		"b": {"a"},
+		"c": {"a"},
+		"d": {"b", "c"},
+	}, "d", "c", "b", "a")
+
+	var mutex sync.Mutex
+	var order []string
+	runner := func(ctx context.Context, task *interfaces.Task) error {
+		mutex.Lock()
+		order = append(order, task.ID)
+		mutex.Unlock()
+		task.Status = interfaces.TaskStatusCompleted
+		return nil
+	}
+
+	scheduler := newPlanScheduler(runner, 2, logger.NewLogrusLogger("error"))
+	require.NoError(t, scheduler.Run(context.Background(), plan))
+
+	require.Len(t, order, 4)
+	assert.Equal(t, "a", order[0])
+	assert.Equal(t, "d", order[3])
+}
+
+func TestPlanSchedulerRunsIndependentTasksConcurrently(t *testing.T) {
+	plan := newTestPlan(nil, "a", "b", "c", "d", "e")
+
+	var current, peak int32
+	runner := func(ctx context.Context, task *interfaces.Task) error {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return nil
+	}
+
+	scheduler := newPlanScheduler(runner, 3, logger.NewLogrusLogger("error"))
+	require.NoError(t, scheduler.Run(context.Background(), plan))
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&peak))
+}
+
+func TestPlanSchedulerSkipsDependentsOfFailedTask(t *testing.T) {
+	plan := newTestPlan(map[string][]string{
+		"b": {"a"},
+		"c": {"b"},
+	}, "a", "b", "c", "x")
+
+	var ran sync.Map
+	runner := func(ctx context.Context, task *interfaces.Task) error {
+		ran.Store(task.ID, true)
+		if task.ID == "a" {
+			task.Status = interfaces.TaskStatusFailed
+			return fmt.Errorf("boom")
+		}
+		task.Status = interfaces.TaskStatusCompleted
+		return nil
+	}
+
+	scheduler := newPlanScheduler(runner, 2, logger.NewLogrusLogger("error"))
+	err := scheduler.Run(context.Background(), plan)
+	require.Error(t, err)
+
+	_, ranX := ran.Load("x")
+	_, ranB := ran.Load("b")
+	assert.True(t, ranX)
+	assert.False(t, ranB)
+	assert.Equal(t, interfaces.TaskStatusSkipped, plan.Tasks[1].Status)
+	assert.Equal(t, interfaces.TaskStatusSkipped, plan.Tasks[2].Status)
+	assert.Equal(t, interfaces.TaskStatusCompleted, plan.Tasks[3].Status)
+}
+
+func TestPlanSchedulerDetectsCycles(t *testing.T) {
+	plan := newTestPlan(map[string][]string{
+		"a": {"b"},
+		"b": {"a"},
+	}, "a", "b")
+
+	runner := func(ctx context.Context, task *interfaces.Task) error {
+		return nil
+	}
+
+	scheduler := newPlanScheduler(runner, 2, logger.NewLogrusLogger("error"))
+	err := scheduler.Run(context.Background(), plan)
+	require.Error(t, err)
+	assert.Equal(t, interfaces.TaskStatusSkipped, plan.Tasks[0].Status)
+}
+
+func TestPlanSchedulerDoesNotRerunCompletedTasks(t *testing.T) {
+	plan := newTestPlan(map[string][]string{
+		"b": {"a"},
+	}, "a", "b")
+	plan.Tasks[0].Status = interfaces.TaskStatusCompleted
+
+	var ran []string
+	runner := func(ctx context.Context, task *interfaces.Task) error {
+		ran = append(ran, task.ID)
+		task.Status = interfaces.TaskStatusCompleted
+		return nil
+	}
+
+	scheduler := newPlanScheduler(runner, 1, logger.NewLogrusLogger("error"))
+	require.NoError(t, scheduler.Run(context.Background(), plan))
+	assert.Equal(t, []string{"b"}, ran)
+}
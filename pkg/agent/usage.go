@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+)
+
+type tokenBudgetKey struct{}
+
+// WithTokenBudget returns a context whose goals and plans may use at most
+// the given number of LLM tokens, overriding Config.TokenBudget. Once the
+// budget is spent, further planning, analysis and agent steps fail.
+func WithTokenBudget(ctx context.Context, tokens int) context.Context {
+	return context.WithValue(ctx, tokenBudgetKey{}, tokens)
+}
+
+// meterPlan returns a context whose LLM usage is metered for a single plan
+func (f *Framework) meterPlan(ctx context.Context) context.Context {
+	budget := f.config.TokenBudget
+	if tokens, ok := ctx.Value(tokenBudgetKey{}).(int); ok {
+		budget = tokens
+	}
+	return llm.WithUsageMeter(ctx, llm.NewUsageMeter(budget))
+}
+
+// recordUsage copies the usage metered so far to the plan. It is only called
+// before a plan is handed to its background run and by the run itself, so
+// callers of ExecuteGoal keep the usage of the snapshot they got.
+func recordUsage(ctx context.Context, plan *interfaces.Plan) {
+	if meter := llm.UsageMeterFrom(ctx); meter != nil {
+		plan.Usage = meter.Snapshot()
+	}
+}
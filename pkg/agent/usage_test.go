@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/planner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingLLMClient reports fixed token counts for every response
+type countingLLMClient struct {
+	*scriptedLLMClient
+}
+
+func (c countingLLMClient) Generate(ctx context.Context, req interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	resp, err := c.scriptedLLMClient.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.PromptEvalCount = 20
+	resp.EvalCount = 10
+	return resp, nil
+}
+
+func TestIterateStopsAtTokenBudget(t *testing.T) {
+	scripted := &scriptedLLMClient{responses: []string{
+		`{"thought": "go", "action": "task", "task": {"type": "test", "description": "step"}}`,
+	}}
+
+	f := newReplanTestFramework(nil, &Config{TokenBudget: 1000})
+	f.usage = llm.NewMeteredClient(countingLLMClient{scripted}, logger.NewLogrusLogger("error"))
+	f.llmClient = f.usage
+
+	var taskIDs []string
+	f.executor.RegisterHandler("test", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		taskIDs = append(taskIDs, task.ID)
+		task.Result = "done"
+		return nil
+	}))
+
+	// The per-call budget overrides the configured one
+	ctx := f.meterPlan(WithTokenBudget(context.Background(), 50))
+	plan := &interfaces.Plan{ID: "plan-1", Goal: "loop"}
+	f.runIterative(ctx, plan)
+
+	assert.Equal(t, interfaces.TaskStatusFailed, plan.Status)
+	assert.Len(t, scripted.prompts, 2, "the request after the budget was crossed is refused")
+	require.Len(t, plan.Tasks, 2)
+
+	require.NotNil(t, plan.Usage)
+	assert.Equal(t, 50, plan.Usage.TokenBudget)
+	assert.Equal(t, 2, plan.Usage.Calls)
+	assert.Equal(t, 40, plan.Usage.PromptTokens)
+	assert.Equal(t, 60, plan.Usage.TotalTokens)
+	assert.Equal(t, 60, plan.Usage.ByStage[llm.StageElementSelection].TotalTokens)
+
+	status := f.usage.Usage()
+	assert.Equal(t, 60, status.TotalTokens)
+	assert.Zero(t, status.TokenBudget)
+}
+
+func TestExecuteGoalReturnsPlanningUsage(t *testing.T) {
+	scripted := &scriptedLLMClient{responses: []string{
+		`{"tasks": [{"name": "summarize", "type": "analysis", "description": "Summarize", "parameters": {"input": "Go is simple"}}]}`,
+		`{"summary": "simple"}`,
+	}}
+
+	f := newReplanTestFramework(nil, &Config{})
+	f.usage = llm.NewMeteredClient(countingLLMClient{scripted}, f.logger)
+	f.llmClient = f.usage
+	f.planner = planner.NewTaskPlanner(f.llmClient, f.memory, f.logger)
+	f.isRunning = true
+	f.executor.RegisterHandler("analysis", taskFunc(func(ctx context.Context, task *interfaces.Task) error {
+		_, err := f.llmClient.Generate(ctx, interfaces.LLMRequest{Prompt: "summarize", Stage: llm.StageAnalysis})
+		return err
+	}))
+
+	plan, err := f.ExecuteGoal(context.Background(), "summarize Go")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		state, err := f.langGraph.GetCurrentState(context.Background(), "plan:"+plan.ID)
+		return err == nil && state == "completed"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The returned plan keeps the usage of planning while the run goes on
+	require.NotNil(t, plan.Usage)
+	assert.Equal(t, 1, plan.Usage.Calls)
+	assert.Equal(t, 2, f.usage.Usage().Calls)
+}
@@ -115,6 +115,8 @@ func (p *PlaywrightAgent) ExecuteAction(ctx context.Context, action interfaces.B
 		return p.handleExtractText(action)
 	case "extract_attribute":
 		return p.handleExtractAttribute(action)
+	case "get_url":
+		return p.page.URL(), nil
 	default:
 		return nil, fmt.Errorf("unsupported action type: %s", action.Type)
 	}
@@ -2,151 +2,429 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/ai-agent-framework/pkg/extract"
 	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/schema"
 )
 
+// Analysis operations supported by the analysis handler
+const (
+	AnalysisSummarize = "summarize"
+	AnalysisExtract   = "extract"
+	AnalysisClassify  = "classify"
+)
+
+// defaultMaxInputChars bounds how much input text is sent to the LLM
+const defaultMaxInputChars = 12000
+
 // AnalysisTaskHandler handles analysis and data processing tasks
 type AnalysisTaskHandler struct {
 	logger      interfaces.Logger
 	memoryStore interfaces.MemoryStore
+	llmClient   interfaces.LLMClient
+	// artifactsDir is the only directory html_file may name a file in
+	artifactsDir string
 }
 
 // NewAnalysisTaskHandler creates a new analysis task handler
-func NewAnalysisTaskHandler(logger interfaces.Logger, memoryStore interfaces.MemoryStore) *AnalysisTaskHandler {
+func NewAnalysisTaskHandler(logger interfaces.Logger, memoryStore interfaces.MemoryStore, llmClient interfaces.LLMClient) *AnalysisTaskHandler {
 	return &AnalysisTaskHandler{
-		logger:      logger,
-		memoryStore: memoryStore,
+		logger:       logger,
+		memoryStore:  memoryStore,
+		llmClient:    llmClient,
+		artifactsDir: ArtifactsDir,
 	}
 }
 
-// Handle executes an analysis task
+// Handle executes an analysis task by running the requested operation over the
+// task inputs through the LLM and storing the structured output
 func (h *AnalysisTaskHandler) Handle(ctx context.Context, task *interfaces.Task) error {
-	h.logger.Info("Executing analysis task", map[string]interface{}{
+	h.logger.WithFields(map[string]interface{}{
 		"task_id":     task.ID,
 		"description": task.Description,
-	})
+	}).Info("Handling analysis task")
 
-	description := task.Description
+	html, err := h.readHTMLInput(task.Parameters)
+	if err != nil {
+		return Permanent(err)
+	}
 
-	// Handle different types of analysis tasks
-	if strings.Contains(strings.ToLower(description), "extract") && strings.Contains(strings.ToLower(description), "links") {
-		return h.extractLinks(ctx, task)
+	operation, _ := task.Parameters["operation"].(string)
+	if operation == "" {
+		operation = inferAnalysisOperation(task.Description, html != "")
 	}
 
-	if strings.Contains(strings.ToLower(description), "analyze") && strings.Contains(strings.ToLower(description), "content") {
-		return h.analyzeContent(ctx, task)
+	// Structural extraction is deterministic and does not need the LLM
+	if isExtractionKind(operation) {
+		return h.handleExtraction(ctx, task, operation, html)
 	}
 
-	// Generic analysis task
-	h.logger.Info("Performing generic analysis", map[string]interface{}{
-		"task_id":     task.ID,
-		"description": description,
+	input, err := h.collectInput(ctx, task, html)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	prompt, err := buildAnalysisPrompt(operation, task, input)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	stage := llm.StageAnalysis
+	if operation == AnalysisSummarize {
+		stage = llm.StageSummarization
+	}
+
+	// Analyses yield to the planning and agent requests that drive a goal
+	resp, err := h.llmClient.Generate(llm.WithPriority(ctx, llm.PriorityLow), interfaces.LLMRequest{
+		Prompt: prompt,
+		Format: "json",
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": 0.2,
+		},
+		Stage: stage,
 	})
+	// Requests the LLM client already retried, or fails fast while the
+	// server is down, are not retried by the task as well
+	if errors.Is(err, llm.ErrTokenBudgetExceeded) || errors.Is(err, llm.ErrRetriesExhausted) || errors.Is(err, llm.ErrCircuitOpen) {
+		return Permanent(fmt.Errorf("failed to run %s analysis: %w", operation, err))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s analysis: %w", operation, err)
+	}
 
-	// Store analysis result in memory
-	analysisResult := map[string]interface{}{
-		"task_id":     task.ID,
-		"type":        "analysis",
-		"description": description,
-		"status":      "completed",
-		"timestamp":   ctx.Value("timestamp"),
+	output, err := parseJSONObject(resp.Response)
+	if err != nil {
+		return Transient(fmt.Errorf("analysis response was not valid JSON: %w", err))
+	}
+
+	return h.storeResult(ctx, task, map[string]interface{}{
+		"action":    "analysis",
+		"operation": operation,
+		"model":     resp.Model,
+		"output":    output,
+	})
+}
+
+// handleExtraction runs a deterministic HTML extraction
+func (h *AnalysisTaskHandler) handleExtraction(ctx context.Context, task *interfaces.Task, kind, html string) error {
+	if html == "" {
+		return Permanent(fmt.Errorf("%s extraction requires an 'html' or 'html_file' parameter", kind))
+	}
+
+	opts, err := extractOptionsFromParameters(task.Parameters)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	output, err := extract.Run(kind, html, opts)
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to extract %s: %w", kind, err))
 	}
 
+	return h.storeResult(ctx, task, map[string]interface{}{
+		"action":    "analysis",
+		"operation": kind,
+		"output":    output,
+	})
+}
+
+// storeResult sets the task result and stores it in memory
+func (h *AnalysisTaskHandler) storeResult(ctx context.Context, task *interfaces.Task, result map[string]interface{}) error {
+	task.Result = result
+
+	// Store analysis result in memory
 	key := fmt.Sprintf("analysis:%s", task.ID)
-	if err := h.memoryStore.Store(ctx, key, analysisResult); err != nil {
-		h.logger.Error("Failed to store analysis result", map[string]interface{}{
+	if err := h.memoryStore.Store(ctx, key, result); err != nil {
+		h.logger.WithFields(map[string]interface{}{
 			"task_id": task.ID,
 			"error":   err.Error(),
-		})
+		}).Error("Failed to store analysis result")
 		return fmt.Errorf("failed to store analysis result: %w", err)
 	}
 
-	h.logger.Info("Analysis task completed", map[string]interface{}{
-		"task_id": task.ID,
-		"result":  "stored in memory",
-	})
+	h.logger.WithFields(map[string]interface{}{
+		"task_id":   task.ID,
+		"operation": result["operation"],
+		"key":       key,
+	}).Info("Analysis task completed")
 
 	return nil
 }
 
-// extractLinks simulates extracting links from search results
-func (h *AnalysisTaskHandler) extractLinks(ctx context.Context, task *interfaces.Task) error {
-	h.logger.Info("Extracting links from search results", map[string]interface{}{
-		"task_id": task.ID,
-	})
+// CanHandle returns true if this handler can handle the given task type
+func (h *AnalysisTaskHandler) CanHandle(taskType string) bool {
+	return taskType == "analysis"
+}
+
+// analysisParameters is the parameter schema of analysis tasks
+var analysisParameters = schema.MustParse(`{
+	"type": "object",
+	"properties": {
+		"operation": {"type": "string", "enum": ["summarize", "extract", "classify", "links", "tables", "meta", "headings", "records", "text"], "description": "Operation; inferred from the description when omitted"},
+		"input": {"description": "Text or data to analyze"},
+		"memory_keys": {"type": "array", "items": {"type": "string"}, "description": "Memory keys whose values are analyzed"},
+		"html": {"type": "string", "description": "HTML to analyze or extract from"},
+		"html_file": {"type": "string", "description": "Path of a saved HTML page such as page_content.html, inside the results directory"},
+		"instructions": {"type": "string", "description": "Instructions for the LLM; defaults to the description"},
+		"fields": {"type": "array", "items": {"type": "string"}, "description": "Field names to extract (extract)"},
+		"record_fields": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Record field name to \"selector\" or \"selector@attribute\" (records)"},
+		"labels": {"type": "array", "items": {"type": "string"}, "minItems": 1, "description": "Candidate labels (classify)"},
+		"max_input_chars": {"type": "integer", "minimum": 1, "description": "Maximum input characters sent to the LLM"},
+		"base_url": {"type": "string", "description": "URL relative links resolve against (links)"},
+		"item_selector": {"type": "string", "description": "CSS selector matching one element per record (records)"}
+	},
+	"additionalProperties": false
+}`)
 
-	// Simulate extracted links
-	links := []string{
-		"https://golang.org/",
-		"https://go.dev/",
-		"https://golang.org/doc/",
-		"https://pkg.go.dev/",
-		"https://golang.org/doc/tutorial/getting-started",
+// Describe returns the actions and parameter schema of analysis tasks
+func (h *AnalysisTaskHandler) Describe() interfaces.HandlerDescription {
+	return interfaces.HandlerDescription{
+		TaskType:    "analysis",
+		Description: "Summarize, extract or classify content with the LLM, or deterministically extract links, tables, meta tags, headings, records or text from HTML",
+		Actions:     []string{AnalysisSummarize, AnalysisExtract, AnalysisClassify, extract.KindLinks, extract.KindTables, extract.KindMeta, extract.KindHeadings, extract.KindRecords, extract.KindText},
+		Parameters:  analysisParameters,
 	}
+}
 
-	// Store links in memory
-	key := fmt.Sprintf("extracted_links:%s", task.ID)
-	if err := h.memoryStore.Store(ctx, key, links); err != nil {
-		h.logger.Error("Failed to store extracted links", map[string]interface{}{
-			"task_id": task.ID,
-			"error":   err.Error(),
-		})
-		return fmt.Errorf("failed to store extracted links: %w", err)
+// collectInput gathers the text to analyze from the "input", "memory_keys",
+// "html" and "html_file" parameters, falling back to the results of the task's
+// dependencies
+func (h *AnalysisTaskHandler) collectInput(ctx context.Context, task *interfaces.Task, html string) (string, error) {
+	var sections []string
+
+	if input, ok := task.Parameters["input"]; ok && input != nil {
+		sections = append(sections, renderInput(input))
 	}
 
-	h.logger.Info("Links extracted and stored", map[string]interface{}{
-		"task_id":    task.ID,
-		"link_count": len(links),
-		"links":      links,
-	})
+	keys, err := stringListParam(task.Parameters["memory_keys"])
+	if err != nil {
+		return "", fmt.Errorf("invalid 'memory_keys' parameter: %w", err)
+	}
+	for _, key := range keys {
+		value, err := h.memoryStore.Retrieve(ctx, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to read memory key %q: %w", key, err)
+		}
+		sections = append(sections, fmt.Sprintf("[%s]\n%s", key, renderInput(value)))
+	}
 
-	return nil
+	if html != "" {
+		doc, err := extract.Parse(html)
+		if err != nil {
+			return "", err
+		}
+		sections = append(sections, doc.Text())
+	}
+
+	if len(sections) == 0 {
+		for _, depID := range task.Dependencies {
+			data, err := h.memoryStore.Retrieve(ctx, "task:"+depID)
+			if err != nil {
+				continue
+			}
+			if dep, ok := data.(*interfaces.Task); ok && dep.Result != nil {
+				sections = append(sections, fmt.Sprintf("[%s: %s]\n%s", dep.Type, dep.Description, renderInput(dep.Result)))
+			}
+		}
+	}
+
+	if len(sections) == 0 {
+		return "", fmt.Errorf("analysis task %s has no input: set 'input', 'memory_keys', 'html' or 'html_file', or depend on a task with a result", task.ID)
+	}
+
+	input := strings.Join(sections, "\n\n")
+
+	maxChars := defaultMaxInputChars
+	if n, ok := numberParam(task.Parameters["max_input_chars"]); ok && n > 0 {
+		maxChars = int(n)
+	}
+	input = TruncateUTF8(input, maxChars)
+
+	return input, nil
 }
 
-// analyzeContent simulates analyzing webpage content
-func (h *AnalysisTaskHandler) analyzeContent(ctx context.Context, task *interfaces.Task) error {
-	h.logger.Info("Analyzing webpage content", map[string]interface{}{
-		"task_id": task.ID,
-	})
+// inferAnalysisOperation picks an operation from the task description,
+// preferring deterministic extraction when HTML input is available
+func inferAnalysisOperation(description string, hasHTML bool) string {
+	desc := strings.ToLower(description)
 
-	// Simulate content analysis
-	analysis := map[string]interface{}{
-		"title":       "Go Programming Language",
-		"description": "Go is an open source programming language that makes it easy to build simple, reliable, and efficient software.",
-		"key_points": []string{
-			"Statically typed, compiled programming language",
-			"Designed at Google by Robert Griesemer, Rob Pike, and Ken Thompson",
-			"Syntactically similar to C, but with memory safety, garbage collection, structural typing, and CSP-style concurrency",
-			"Fast compilation and execution",
-			"Built-in support for concurrent programming",
-		},
-		"relevance": "high",
-		"source":    "official Go website",
+	if hasHTML {
+		switch {
+		case strings.Contains(desc, "link"):
+			return extract.KindLinks
+		case strings.Contains(desc, "table"):
+			return extract.KindTables
+		case strings.Contains(desc, "heading") || strings.Contains(desc, "outline"):
+			return extract.KindHeadings
+		case strings.Contains(desc, "meta"):
+			return extract.KindMeta
+		}
 	}
 
-	// Store analysis in memory
-	key := fmt.Sprintf("content_analysis:%s", task.ID)
-	if err := h.memoryStore.Store(ctx, key, analysis); err != nil {
-		h.logger.Error("Failed to store content analysis", map[string]interface{}{
-			"task_id": task.ID,
-			"error":   err.Error(),
-		})
-		return fmt.Errorf("failed to store content analysis: %w", err)
+	switch {
+	case strings.Contains(desc, "extract"):
+		return AnalysisExtract
+	case strings.Contains(desc, "classif") || strings.Contains(desc, "categor"):
+		return AnalysisClassify
+	default:
+		return AnalysisSummarize
 	}
+}
 
-	h.logger.Info("Content analysis completed and stored", map[string]interface{}{
-		"task_id": task.ID,
-		"title":   analysis["title"],
-		"points":  len(analysis["key_points"].([]string)),
-	})
+// buildAnalysisPrompt creates the prompt for an analysis operation
+func buildAnalysisPrompt(operation string, task *interfaces.Task, input string) (string, error) {
+	instructions, _ := task.Parameters["instructions"].(string)
+	if instructions == "" {
+		instructions = task.Description
+	}
 
-	return nil
+	var format string
+	switch operation {
+	case AnalysisSummarize:
+		format = `{"summary": "short summary", "key_points": ["point", "..."]}`
+	case AnalysisExtract:
+		fields, err := stringListParam(task.Parameters["fields"])
+		if err != nil {
+			return "", fmt.Errorf("invalid 'fields' parameter: %w", err)
+		}
+		if len(fields) == 0 {
+			format = `{"items": [{"field": "value"}]}`
+		} else {
+			example := make(map[string]string, len(fields))
+			for _, field := range fields {
+				example[field] = "..."
+			}
+			encoded, _ := json.Marshal(map[string]interface{}{"items": []interface{}{example}})
+			format = string(encoded)
+		}
+	case AnalysisClassify:
+		labels, err := stringListParam(task.Parameters["labels"])
+		if err != nil {
+			return "", fmt.Errorf("invalid 'labels' parameter: %w", err)
+		}
+		if len(labels) == 0 {
+			return "", fmt.Errorf("classify analysis requires a 'labels' parameter")
+		}
+		format = fmt.Sprintf(`{"label": "one of: %s", "confidence": 0.0, "rationale": "why"}`, strings.Join(labels, ", "))
+	default:
+		return "", fmt.Errorf("unsupported analysis operation: %s", operation)
+	}
+
+	return fmt.Sprintf(`You are a precise data analyst. Perform the "%s" operation on the input below.
+
+Task: %s
+
+Input:
+%s
+
+Respond with a single JSON object and nothing else, using this structure:
+%s
+
+Response:`, operation, instructions, input, format), nil
 }
 
-// CanHandle returns true if this handler can handle the given task type
-func (h *AnalysisTaskHandler) CanHandle(taskType string) bool {
-	return taskType == "analysis"
+// renderInput converts an input value into text for the prompt
+func renderInput(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+// parseJSONObject extracts the first JSON object from an LLM response
+func parseJSONObject(response string) (map[string]interface{}, error) {
+	encoded, err := llm.ExtractJSONObject(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal([]byte(encoded), &object); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// readHTMLInput returns HTML passed inline via "html" or saved on disk, such as
+// a page_content.html artifact, via "html_file". Files must lie inside the
+// artifacts directory, since the path usually comes from the planner LLM.
+func (h *AnalysisTaskHandler) readHTMLInput(params map[string]interface{}) (string, error) {
+	if html, ok := params["html"].(string); ok && html != "" {
+		return html, nil
+	}
+
+	if path, ok := params["html_file"].(string); ok && path != "" {
+		resolved, err := resolveWithin(h.artifactsDir, path)
+		if err != nil {
+			return "", fmt.Errorf("invalid html file: %w", err)
+		}
+		content, err := os.ReadFile(resolved)
+		if err != nil {
+			return "", fmt.Errorf("failed to read html file: %w", err)
+		}
+		return string(content), nil
+	}
+
+	return "", nil
+}
+
+// TruncateUTF8 shortens s to at most limit bytes without splitting a rune
+func TruncateUTF8(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// isExtractionKind reports whether an operation is a deterministic extraction
+func isExtractionKind(operation string) bool {
+	for _, kind := range extract.Kinds {
+		if operation == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// extractOptionsFromParameters reads the "base_url", "item_selector" and
+// "record_fields" (name to "selector" or "selector@attribute") parameters
+func extractOptionsFromParameters(params map[string]interface{}) (extract.Options, error) {
+	opts := extract.Options{}
+	opts.BaseURL, _ = params["base_url"].(string)
+	opts.ItemSelector, _ = params["item_selector"].(string)
+
+	if raw, ok := params["record_fields"]; ok && raw != nil {
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return opts, fmt.Errorf("'record_fields' must map field names to selectors for records extraction")
+		}
+		opts.Fields = make(map[string]string, len(fields))
+		for name, spec := range fields {
+			selector, ok := spec.(string)
+			if !ok {
+				return opts, fmt.Errorf("selector for field %q must be a string", name)
+			}
+			opts.Fields[name] = selector
+		}
+	}
+
+	return opts, nil
 }
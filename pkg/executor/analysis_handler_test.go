@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/extract"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLLMClient returns a canned response or error and records the prompts it
+// receives
+type stubLLMClient struct {
+	response string
+	err      error
+	prompts  []string
+}
+
+func (c *stubLLMClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.prompts = append(c.prompts, request.Prompt)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &interfaces.LLMResponse{Model: "stub", Response: c.response, Done: true}, nil
+}
+
+func (c *stubLLMClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *stubLLMClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *stubLLMClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+func TestAnalysisTaskHandlerSummarizesDependencyResults(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	store := memory.NewInMemoryStore(log)
+	llm := &stubLLMClient{response: `<think>hmm</think>{"summary": "Go is simple", "key_points": ["fast"]}`}
+	handler := NewAnalysisTaskHandler(log, store, llm)
+
+	ctx := context.Background()
+	dep := &interfaces.Task{
+		ID:          "extract",
+		Type:        "browser",
+		Description: "Extract headings",
+		Status:      interfaces.TaskStatusCompleted,
+		Result:      map[string]interface{}{"result": "The Go Programming Language"},
+	}
+	require.NoError(t, store.Store(ctx, "task:extract", dep))
+
+	task := &interfaces.Task{
+		ID:           "summary",
+		Type:         "analysis",
+		Description:  "Summarize the page",
+		Dependencies: []string{"extract"},
+		Parameters:   map[string]interface{}{},
+	}
+	require.NoError(t, handler.Handle(ctx, task))
+
+	require.Len(t, llm.prompts, 1)
+	assert.Contains(t, llm.prompts[0], "The Go Programming Language")
+
+	result := task.Result.(map[string]interface{})
+	assert.Equal(t, AnalysisSummarize, result["operation"])
+	assert.Equal(t, "Go is simple", result["output"].(map[string]interface{})["summary"])
+
+	stored, err := store.Retrieve(ctx, "analysis:summary")
+	require.NoError(t, err)
+	assert.Equal(t, result, stored)
+}
+
+func TestAnalysisTaskHandlerRequiresInput(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), &stubLLMClient{})
+
+	task := &interfaces.Task{ID: "t1", Type: "analysis", Description: "Analyze content", Parameters: map[string]interface{}{}}
+	err := handler.Handle(context.Background(), task)
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+}
+
+func TestAnalysisTaskHandlerDoesNotRetryExhaustedLLMRequests(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	stub := &stubLLMClient{err: &llm.StatusError{Server: "Ollama", StatusCode: 503}}
+	client := llm.NewResilientClient(stub, llm.ResilienceConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond}, log)
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), client)
+
+	task := &interfaces.Task{ID: "t1", Type: "analysis", Description: "Summarize", Parameters: map[string]interface{}{"input": "Go is simple"}}
+	err := handler.Handle(context.Background(), task)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, llm.ErrRetriesExhausted)
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	assert.Len(t, stub.prompts, 2)
+}
+
+func TestAnalysisTaskHandlerClassifiesWithLabels(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	llm := &stubLLMClient{response: `{"label": "docs", "confidence": 0.9, "rationale": "reference material"}`}
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), llm)
+
+	task := &interfaces.Task{
+		ID:          "t1",
+		Type:        "analysis",
+		Description: "Classify the page",
+		Parameters: map[string]interface{}{
+			"input":  "Package documentation for net/http",
+			"labels": []interface{}{"docs", "blog", "news"},
+		},
+	}
+	require.NoError(t, handler.Handle(context.Background(), task))
+
+	assert.Contains(t, llm.prompts[0], "docs, blog, news")
+	output := task.Result.(map[string]interface{})["output"].(map[string]interface{})
+	assert.Equal(t, "docs", output["label"])
+}
+
+func TestAnalysisTaskHandlerExtractsLinksFromSavedPage(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	llm := &stubLLMClient{}
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), llm)
+	handler.artifactsDir = t.TempDir()
+
+	path := filepath.Join(handler.artifactsDir, "page_content.html")
+	page := `<html><body><a href="/docs">Docs</a><a href="https://go.dev/blog">Blog</a></body></html>`
+	require.NoError(t, os.WriteFile(path, []byte(page), 0644))
+
+	task := &interfaces.Task{
+		ID:          "t1",
+		Type:        "analysis",
+		Description: "Extract the links from the page",
+		Parameters: map[string]interface{}{
+			"html_file": path,
+			"base_url":  "https://go.dev/",
+		},
+	}
+	require.NoError(t, handler.Handle(context.Background(), task))
+
+	assert.Empty(t, llm.prompts, "link extraction should not call the LLM")
+	result := task.Result.(map[string]interface{})
+	assert.Equal(t, extract.KindLinks, result["operation"])
+	assert.Equal(t, []extract.Link{
+		{URL: "https://go.dev/docs", Text: "Docs"},
+		{URL: "https://go.dev/blog", Text: "Blog"},
+	}, result["output"])
+}
+
+func TestAnalysisTaskHandlerReadsHTMLFilesOnlyFromArtifacts(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), &stubLLMClient{})
+	handler.artifactsDir = t.TempDir()
+
+	outside := filepath.Join(t.TempDir(), "secret.html")
+	require.NoError(t, os.WriteFile(outside, []byte("<p>secret</p>"), 0644))
+
+	task := &interfaces.Task{
+		ID:         "t1",
+		Type:       "analysis",
+		Parameters: map[string]interface{}{"operation": "links", "html_file": outside},
+	}
+	err := handler.Handle(context.Background(), task)
+	assert.ErrorContains(t, err, "is outside")
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+}
+
+func TestTruncateUTF8KeepsRunesWhole(t *testing.T) {
+	assert.Equal(t, "héllo", TruncateUTF8("héllo", 10))
+	assert.Equal(t, "h", TruncateUTF8("héllo", 2), "é takes two bytes")
+	assert.Equal(t, "hé", TruncateUTF8("héllo", 3))
+}
+
+func TestAnalysisTaskHandlerExtractsRecords(t *testing.T) {
+	log := logger.NewLogrusLogger("error")
+	handler := NewAnalysisTaskHandler(log, memory.NewInMemoryStore(log), &stubLLMClient{})
+
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "analysis",
+		Parameters: map[string]interface{}{
+			"operation":     extract.KindRecords,
+			"html":          `<ul><li><a href="mailto:a@example.com">Ann</a></li><li><a href="/bob">Bob</a></li></ul>`,
+			"item_selector": "li",
+			"record_fields": map[string]interface{}{
+				"name":  "a",
+				"email": `a[href^="mailto:"]@href`,
+			},
+		},
+	}
+	require.NoError(t, handler.Handle(context.Background(), task))
+
+	assert.Equal(t, []map[string]string{
+		{"name": "Ann", "email": "mailto:a@example.com"},
+		{"name": "Bob"},
+	}, task.Result.(map[string]interface{})["output"])
+}
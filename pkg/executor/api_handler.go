@@ -0,0 +1,376 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/schema"
+)
+
+// maxAPIResponseBytes caps how much of a response body is read into memory
+const maxAPIResponseBytes = 10 << 20
+
+// APITaskHandler handles HTTP API call tasks
+type APITaskHandler struct {
+	httpClient *http.Client
+	logger     interfaces.Logger
+}
+
+// NewAPITaskHandler creates a new API task handler
+func NewAPITaskHandler(logger interfaces.Logger) *APITaskHandler {
+	return NewAPITaskHandlerWithClient(&http.Client{}, logger)
+}
+
+// NewAPITaskHandlerWithClient creates a new API task handler using the given HTTP client
+func NewAPITaskHandlerWithClient(httpClient *http.Client, logger interfaces.Logger) *APITaskHandler {
+	return &APITaskHandler{
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+// Handle executes an API task
+func (h *APITaskHandler) Handle(ctx context.Context, task *interfaces.Task) error {
+	h.logger.WithFields(map[string]interface{}{
+		"task_id":     task.ID,
+		"description": task.Description,
+	}).Info("Handling API task")
+
+	req, err := h.buildRequest(ctx, task.Parameters)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	// Requests that may have reached the server are only repeated when
+	// repeating them cannot duplicate side effects
+	retryable := isIdempotent(req.Method) || task.Parameters["idempotent"] == true
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to call %s %s: %w", req.Method, req.URL.Redacted(), err)
+		if !retryable {
+			return Permanent(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	rawBody, err := io.ReadAll(io.LimitReader(resp.Body, maxAPIResponseBytes+1))
+	if err != nil {
+		// The server has already handled the request
+		err = fmt.Errorf("failed to read response body: %w", err)
+		if !retryable {
+			return Permanent(err)
+		}
+		return err
+	}
+	truncated := len(rawBody) > maxAPIResponseBytes
+	if truncated {
+		rawBody = rawBody[:maxAPIResponseBytes]
+	}
+
+	// Decode JSON bodies so later tasks can reference individual fields
+	var body interface{} = string(rawBody)
+	if !truncated && isJSONResponse(resp, rawBody) {
+		var decoded interface{}
+		if err := json.Unmarshal(rawBody, &decoded); err == nil {
+			body = decoded
+		}
+	}
+
+	headers := make(map[string]interface{}, len(resp.Header))
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+
+	result := map[string]interface{}{
+		"action":      "api",
+		"method":      req.Method,
+		"url":         req.URL.Redacted(),
+		"status_code": resp.StatusCode,
+		"headers":     headers,
+		"body":        body,
+		"truncated":   truncated,
+	}
+	task.Result = result
+
+	h.logger.WithFields(map[string]interface{}{
+		"task_id":     task.ID,
+		"method":      req.Method,
+		"url":         req.URL.Redacted(),
+		"status_code": resp.StatusCode,
+	}).Info("API call completed")
+
+	if err := checkExpectedStatus(task.Parameters["expect_status"], resp.StatusCode); err != nil {
+		// Server errors are usually transient, anything else will not change on retry
+		if resp.StatusCode >= 500 && retryable {
+			return Transient(err)
+		}
+		return Permanent(err)
+	}
+
+	if extract, ok := task.Parameters["extract"].(map[string]interface{}); ok {
+		extracted := make(map[string]interface{}, len(extract))
+		for name, rawPath := range extract {
+			path, ok := rawPath.(string)
+			if !ok {
+				return Permanent(fmt.Errorf("extract path for %q must be a string", name))
+			}
+
+			value, err := evaluateJSONPath(body, path)
+			if err != nil {
+				return Permanent(fmt.Errorf("failed to extract %q with %s: %w", name, path, err))
+			}
+			extracted[name] = value
+		}
+		result["extracted"] = extracted
+	}
+
+	return nil
+}
+
+// CanHandle returns true if this handler can handle the given task type
+func (h *APITaskHandler) CanHandle(taskType string) bool {
+	return taskType == "api"
+}
+
+// apiParameters is the parameter schema of api tasks
+var apiParameters = schema.MustParse(`{
+	"type": "object",
+	"properties": {
+		"method": {"type": "string", "description": "HTTP method such as GET or POST; defaults to GET"},
+		"url": {"type": "string", "description": "Absolute http or https URL"},
+		"query": {"type": "object", "description": "Query string parameters; list values repeat the key"},
+		"json": {"description": "Request body encoded as JSON"},
+		"form": {"type": "object", "description": "Request body encoded as a form"},
+		"body": {"description": "Raw request body"},
+		"headers": {"type": "object", "additionalProperties": {"type": ["string", "number", "boolean"]}, "description": "Request headers"},
+		"auth": {
+			"type": "object",
+			"properties": {
+				"type": {"type": "string", "enum": ["bearer", "basic"]},
+				"token": {"type": "string"},
+				"username": {"type": "string"},
+				"password": {"type": "string"}
+			},
+			"required": ["type"],
+			"additionalProperties": false
+		},
+		"expect_status": {"type": ["number", "string", "array"], "description": "Accepted status code, list of codes or class such as \"2xx\"; defaults to 2xx"},
+		"extract": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Result name to JSONPath such as \"$.data.items[0].id\""},
+		"idempotent": {"type": "boolean", "description": "Set to true to retry POST or PATCH requests that are safe to repeat; other methods are retried by default"}
+	},
+	"required": ["url"],
+	"additionalProperties": false
+}`)
+
+// Describe returns the actions and parameter schema of api tasks
+func (h *APITaskHandler) Describe() interfaces.HandlerDescription {
+	return interfaces.HandlerDescription{
+		TaskType:    "api",
+		Description: "Call an HTTP API and extract fields from the JSON response",
+		Actions:     []string{"request"},
+		Parameters:  apiParameters,
+	}
+}
+
+// isIdempotent reports whether repeating a request with the method has the
+// same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// buildRequest creates the HTTP request described by the task parameters
+func (h *APITaskHandler) buildRequest(ctx context.Context, params map[string]interface{}) (*http.Request, error) {
+	rawURL, ok := params["url"].(string)
+	if !ok || rawURL == "" {
+		return nil, fmt.Errorf("missing or invalid 'url' parameter for api task")
+	}
+
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported url scheme %q", target.Scheme)
+	}
+
+	method := http.MethodGet
+	if m, ok := params["method"].(string); ok && m != "" {
+		method = strings.ToUpper(m)
+	}
+
+	if query, ok := params["query"].(map[string]interface{}); ok {
+		values := target.Query()
+		for key, value := range query {
+			switch v := value.(type) {
+			case []interface{}:
+				for _, item := range v {
+					values.Add(key, stringifyParam(item))
+				}
+			default:
+				values.Set(key, stringifyParam(v))
+			}
+		}
+		target.RawQuery = values.Encode()
+	}
+
+	var body io.Reader
+	contentType := ""
+	switch {
+	case params["json"] != nil:
+		encoded, err := json.Marshal(params["json"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode json body: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	case params["form"] != nil:
+		form, ok := params["form"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("'form' parameter must be an object")
+		}
+		values := url.Values{}
+		for key, value := range form {
+			values.Set(key, stringifyParam(value))
+		}
+		body = strings.NewReader(values.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	case params["body"] != nil:
+		body = strings.NewReader(stringifyParam(params["body"]))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if headers, ok := params["headers"].(map[string]interface{}); ok {
+		for key, value := range headers {
+			req.Header.Set(key, stringifyParam(value))
+		}
+	}
+
+	if auth, ok := params["auth"].(map[string]interface{}); ok {
+		if err := applyAuth(req, auth); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// applyAuth adds bearer or basic credentials to the request
+func applyAuth(req *http.Request, auth map[string]interface{}) error {
+	authType, _ := auth["type"].(string)
+
+	switch strings.ToLower(authType) {
+	case "bearer":
+		token, ok := auth["token"].(string)
+		if !ok || token == "" {
+			return fmt.Errorf("bearer auth requires a 'token'")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case "basic":
+		username, _ := auth["username"].(string)
+		password, _ := auth["password"].(string)
+		if username == "" {
+			return fmt.Errorf("basic auth requires a 'username'")
+		}
+		req.SetBasicAuth(username, password)
+	default:
+		return fmt.Errorf("unsupported auth type: %q", authType)
+	}
+
+	return nil
+}
+
+// checkExpectedStatus verifies the response status against the "expect_status"
+// parameter, which may be a status code, a list of codes or a class like "2xx".
+// Without the parameter any 2xx status is accepted.
+func checkExpectedStatus(expected interface{}, status int) error {
+	var accepted []interface{}
+	switch v := expected.(type) {
+	case nil:
+		accepted = []interface{}{"2xx"}
+	case []interface{}:
+		accepted = v
+	default:
+		accepted = []interface{}{v}
+	}
+
+	for _, candidate := range accepted {
+		switch c := candidate.(type) {
+		case string:
+			if len(c) == 3 && strings.HasSuffix(strings.ToLower(c), "xx") && c[0] == byte('0'+status/100) {
+				return nil
+			}
+			if c == fmt.Sprint(status) {
+				return nil
+			}
+		default:
+			if n, ok := numberParam(c); ok && int(n) == status {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("unexpected response status %d (expected %v)", status, accepted)
+}
+
+// evaluateJSONPath evaluates a simple JSONPath expression such as
+// $.data.items[0].id against a decoded JSON document
+func evaluateJSONPath(document interface{}, path string) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path must start with '$'")
+	}
+
+	return lookupPath(document, splitPath(strings.TrimPrefix(path, "$")))
+}
+
+// isJSONResponse reports whether a response body should be decoded as JSON
+func isJSONResponse(resp *http.Response, body []byte) bool {
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// stringifyParam renders a parameter value for use in a URL, header or body
+func stringifyParam(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprint(v)
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
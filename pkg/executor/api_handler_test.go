@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITaskHandlerPostsJSONAndExtracts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "go", r.URL.Query().Get("q"))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "value", payload["key"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"items": [{"id": 42}, {"id": 43}]}}`))
+	}))
+	defer server.Close()
+
+	handler := NewAPITaskHandler(logger.NewLogrusLogger("error"))
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "api",
+		Parameters: map[string]interface{}{
+			"method": "post",
+			"url":    server.URL,
+			"query":  map[string]interface{}{"q": "go"},
+			"json":   map[string]interface{}{"key": "value"},
+			"auth":   map[string]interface{}{"type": "bearer", "token": "secret"},
+			"extract": map[string]interface{}{
+				"first_id": "$.data.items[0].id",
+			},
+		},
+	}
+
+	require.NoError(t, handler.Handle(context.Background(), task))
+
+	result := task.Result.(map[string]interface{})
+	assert.Equal(t, http.StatusOK, result["status_code"])
+	assert.Equal(t, map[string]interface{}{"first_id": float64(42)}, result["extracted"])
+}
+
+func TestAPITaskHandlerChecksExpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "pw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	handler := NewAPITaskHandler(logger.NewLogrusLogger("error"))
+
+	unauthorized := &interfaces.Task{ID: "t1", Type: "api", Parameters: map[string]interface{}{"url": server.URL}}
+	err := handler.Handle(context.Background(), unauthorized)
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+
+	unavailable := &interfaces.Task{ID: "t2", Type: "api", Parameters: map[string]interface{}{
+		"url":  server.URL,
+		"auth": map[string]interface{}{"type": "basic", "username": "alice", "password": "pw"},
+	}}
+	err = handler.Handle(context.Background(), unavailable)
+	require.Error(t, err)
+	assert.Equal(t, ErrorClassTransient, ClassifyError(err))
+
+	accepted := &interfaces.Task{ID: "t3", Type: "api", Parameters: map[string]interface{}{
+		"url":           server.URL,
+		"expect_status": []interface{}{float64(401), "5xx"},
+	}}
+	assert.NoError(t, handler.Handle(context.Background(), accepted))
+}
+
+func TestAPITaskHandlerRetriesOnlyIdempotentRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	handler := NewAPITaskHandler(logger.NewLogrusLogger("error"))
+
+	post := &interfaces.Task{ID: "t1", Type: "api", Parameters: map[string]interface{}{"method": "POST", "url": server.URL}}
+	err := handler.Handle(context.Background(), post)
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err), "a POST may have taken effect")
+
+	post.Parameters["idempotent"] = true
+	err = handler.Handle(context.Background(), post)
+	assert.Equal(t, ErrorClassTransient, ClassifyError(err))
+
+	put := &interfaces.Task{ID: "t2", Type: "api", Parameters: map[string]interface{}{"method": "PUT", "url": server.URL}}
+	err = handler.Handle(context.Background(), put)
+	assert.Equal(t, ErrorClassTransient, ClassifyError(err))
+}
+
+func TestAPITaskHandlerDoesNotRetryPostWhoseResponseBreaksOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"id": `))
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}))
+	defer server.Close()
+
+	handler := NewAPITaskHandler(logger.NewLogrusLogger("error"))
+
+	post := &interfaces.Task{ID: "t1", Type: "api", Parameters: map[string]interface{}{"method": "POST", "url": server.URL}}
+	err := handler.Handle(context.Background(), post)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read response body")
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+
+	get := &interfaces.Task{ID: "t2", Type: "api", Parameters: map[string]interface{}{"url": server.URL}}
+	err = handler.Handle(context.Background(), get)
+	assert.Equal(t, ErrorClassNetwork, ClassifyError(err))
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ai-agent-framework/pkg/extract"
 	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/schema"
 )
 
 // BrowserTaskHandler handles browser-related tasks
@@ -82,6 +84,35 @@ func (h *BrowserTaskHandler) CanHandle(taskType string) bool {
 	return taskType == "browser"
 }
 
+// browserParameters is the parameter schema of browser tasks
+var browserParameters = schema.MustParse(`{
+	"type": "object",
+	"properties": {
+		"action": {"type": "string", "enum": ["navigate", "click", "type", "extract", "screenshot", "wait"], "description": "Browser action; inferred from the description when omitted"},
+		"url": {"type": "string", "description": "Absolute URL to open (navigate)"},
+		"selector": {"type": "string", "description": "CSS selector of the target element (click, type, wait, and extract of text or attribute)"},
+		"text": {"type": "string", "description": "Text to type (type)"},
+		"extract_type": {"type": "string", "enum": ["text", "attribute", "links", "tables", "meta", "headings", "records"], "description": "What to extract; defaults to text"},
+		"attribute": {"type": "string", "description": "Attribute to read (extract_type attribute)"},
+		"base_url": {"type": "string", "description": "URL relative links resolve against (extract_type links)"},
+		"item_selector": {"type": "string", "description": "CSS selector matching one element per record (extract_type records)"},
+		"record_fields": {"type": "object", "additionalProperties": {"type": "string"}, "description": "Record field name to \"selector\" or \"selector@attribute\" (extract_type records)"},
+		"timeout": {"type": "number", "minimum": 0, "description": "Milliseconds to wait for the selector (wait)"},
+		"filename": {"type": "string", "description": "File name of the screenshot (screenshot)"}
+	},
+	"additionalProperties": false
+}`)
+
+// Describe returns the actions and parameter schema of browser tasks
+func (h *BrowserTaskHandler) Describe() interfaces.HandlerDescription {
+	return interfaces.HandlerDescription{
+		TaskType:    "browser",
+		Description: "Web browser automation: navigate, interact with elements and extract page content",
+		Actions:     []string{"navigate", "click", "type", "extract", "screenshot", "wait"},
+		Parameters:  browserParameters,
+	}
+}
+
 func (h *BrowserTaskHandler) handleNavigate(ctx context.Context, task *interfaces.Task) error {
 	url, ok := task.Parameters["url"].(string)
 	if !ok {
@@ -197,14 +228,19 @@ func (h *BrowserTaskHandler) handleType(ctx context.Context, task *interfaces.Ta
 }
 
 func (h *BrowserTaskHandler) handleExtract(ctx context.Context, task *interfaces.Task) error {
-	selector, ok := task.Parameters["selector"].(string)
+	extractType, ok := task.Parameters["extract_type"].(string)
 	if !ok {
-		return fmt.Errorf("missing or invalid 'selector' parameter for extract action")
+		extractType = "text" // default to text extraction
 	}
 
-	extractType, ok := task.Parameters["extract_type"].(string)
+	switch extractType {
+	case extract.KindLinks, extract.KindTables, extract.KindMeta, extract.KindHeadings, extract.KindRecords:
+		return h.handleExtractPage(ctx, task, extractType)
+	}
+
+	selector, ok := task.Parameters["selector"].(string)
 	if !ok {
-		extractType = "text" // default to text extraction
+		return fmt.Errorf("missing or invalid 'selector' parameter for extract action")
 	}
 
 	var action interfaces.BrowserAction
@@ -250,6 +286,43 @@ func (h *BrowserTaskHandler) handleExtract(ctx context.Context, task *interfaces
 	return nil
 }
 
+// handleExtractPage runs a structured extraction over the current page content
+func (h *BrowserTaskHandler) handleExtractPage(ctx context.Context, task *interfaces.Task, extractType string) error {
+	content, err := h.browserAgent.GetPageContent(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get page content: %w", err)
+	}
+
+	opts, err := extractOptionsFromParameters(task.Parameters)
+	if err != nil {
+		return Permanent(err)
+	}
+	if opts.BaseURL == "" {
+		// Resolve relative links against the page the browser is on
+		if pageURL, err := h.browserAgent.ExecuteAction(ctx, interfaces.BrowserAction{Type: "get_url"}); err == nil {
+			opts.BaseURL, _ = pageURL.(string)
+		}
+	}
+
+	result, err := extract.Run(extractType, content, opts)
+	if err != nil {
+		return Permanent(fmt.Errorf("failed to extract %s from page: %w", extractType, err))
+	}
+
+	task.Result = map[string]interface{}{
+		"action":       "extract",
+		"extract_type": extractType,
+		"result":       result,
+	}
+
+	// Save the task result
+	if err := h.saveTaskResult(ctx, task, task.Result); err != nil {
+		h.logger.WithField("error", err).Warn("Failed to save task result")
+	}
+
+	return nil
+}
+
 func (h *BrowserTaskHandler) handleScreenshot(ctx context.Context, task *interfaces.Task) error {
 	screenshot, err := h.browserAgent.Screenshot(ctx)
 	if err != nil {
@@ -485,11 +558,15 @@ func (h *BrowserTaskHandler) isSearchAction(description string) bool {
 	return strings.Contains(desc, "search") || strings.Contains(desc, "enter") || strings.Contains(desc, "type")
 }
 
+// ArtifactsDir is the directory browser tasks save screenshots, page content
+// and results to, one timestamped folder per task
+const ArtifactsDir = "results"
+
 // saveTaskResult saves the task result to a timestamped folder in the results directory
 func (h *BrowserTaskHandler) saveTaskResult(ctx context.Context, task *interfaces.Task, result interface{}) error {
 	// Create results directory with timestamp
 	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	resultsDir := filepath.Join("results", timestamp)
+	resultsDir := filepath.Join(ArtifactsDir, timestamp)
 
 	if err := os.MkdirAll(resultsDir, 0755); err != nil {
 		h.logger.WithField("error", err).Warn("Failed to create results directory")
@@ -0,0 +1,23 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrowserParametersAcceptInferredDefaults(t *testing.T) {
+	handler := NewBrowserTaskHandler(nil, logger.NewLogrusLogger("error"))
+
+	for _, action := range handler.Describe().Actions {
+		task := &interfaces.Task{
+			ID:          "t1",
+			Description: "take a screenshot of the search results",
+			Parameters:  map[string]interface{}{"action": action},
+		}
+		handler.setParametersFromDescription(task)
+		require.NoError(t, validateParameters(handler, "browser", task.ID, task.Parameters), action)
+	}
+}
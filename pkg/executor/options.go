@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// WithRetryPolicy sets the default retry policy for every task of the handler's type
+func WithRetryPolicy(policy *interfaces.RetryPolicy) interfaces.HandlerOption {
+	return func(config *interfaces.HandlerConfig) {
+		config.RetryPolicy = policy
+	}
+}
+
+// WithTimeout sets the default timeout of each attempt of every task of the
+// handler's type. A task that retries may run for up to the policy's attempts
+// times this timeout plus backoff.
+func WithTimeout(timeout time.Duration) interfaces.HandlerOption {
+	return func(config *interfaces.HandlerConfig) {
+		config.Timeout = timeout
+	}
+}
+
+// taskTimeoutFromParameters reads a task's "task_timeout" parameter, the
+// timeout of each attempt rather than of the whole task, falling back to the
+// handler default when the task does not specify one
+func taskTimeoutFromParameters(params map[string]interface{}, fallback time.Duration) (time.Duration, error) {
+	raw, ok := params["task_timeout"]
+	if !ok {
+		return fallback, nil
+	}
+
+	timeout, err := durationParam(raw)
+	if err != nil {
+		return 0, err
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("timeout must not be negative: %s", timeout)
+	}
+
+	return timeout, nil
+}
+
+// numberParam converts a numeric parameter value to float64
+func numberParam(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// durationParam parses a duration parameter given either as a Go duration
+// string ("1.5s") or as a number of milliseconds
+func durationParam(value interface{}) (time.Duration, error) {
+	if s, ok := value.(string); ok {
+		return time.ParseDuration(s)
+	}
+
+	if n, ok := numberParam(value); ok {
+		return time.Duration(n * float64(time.Millisecond)), nil
+	}
+
+	return 0, fmt.Errorf("unsupported duration value: %v", value)
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveWithin resolves an existing path and checks that it lies inside root
+// once symlinks are followed. Relative paths are taken from the agent's working
+// directory, like the artifact paths browser tasks report.
+func resolveWithin(root, path string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return "", fmt.Errorf("directory %s does not exist", root)
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("%q does not exist", path)
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q is outside %s", path, root)
+	}
+	return resolved, nil
+}
+
+// checkWithin checks that path lies inside root once symlinks are followed.
+// A path that does not exist is checked through its closest existing parent.
+func checkWithin(root, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	for existing := path; ; existing = filepath.Dir(existing) {
+		if _, err := os.Lstat(existing); err == nil {
+			if _, err := resolveWithin(root, existing); err != nil {
+				return fmt.Errorf("%q is outside %s", path, root)
+			}
+			return nil
+		}
+		if existing == filepath.Dir(existing) {
+			return fmt.Errorf("%q is outside %s", path, root)
+		}
+	}
+}
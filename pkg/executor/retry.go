@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// Error classes used to decide whether a failed attempt is retried
+const (
+	ErrorClassTimeout   = "timeout"
+	ErrorClassNetwork   = "network"
+	ErrorClassTransient = "transient"
+	ErrorClassCancelled = "cancelled"
+	ErrorClassPermanent = "permanent"
+	ErrorClassUnknown   = "unknown"
+
+	// ErrorClassAny matches every class except cancelled and permanent
+	ErrorClassAny = "any"
+)
+
+// DefaultRetryOn lists the error classes retried when a policy does not specify any
+var DefaultRetryOn = []string{ErrorClassTimeout, ErrorClassNetwork, ErrorClassTransient}
+
+// DefaultRetryPolicy returns a policy with exponential backoff and jitter
+func DefaultRetryPolicy(maxAttempts int) *interfaces.RetryPolicy {
+	return &interfaces.RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryOn:        DefaultRetryOn,
+	}
+}
+
+// classifiedError tags an error with an explicit error class
+type classifiedError struct {
+	class string
+	err   error
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// Transient marks an error as safe to retry
+func Transient(err error) error {
+	return &classifiedError{class: ErrorClassTransient, err: err}
+}
+
+// Permanent marks an error that must never be retried
+func Permanent(err error) error {
+	return &classifiedError{class: ErrorClassPermanent, err: err}
+}
+
+// ClassifyError determines the error class of a handler error
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return classified.class
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassCancelled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassNetwork
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ErrorClassNetwork
+	}
+
+	// Browser automation errors only carry their cause in the message
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "timeout") || strings.Contains(message, "timed out"):
+		return ErrorClassTimeout
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "connection reset"):
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassUnknown
+}
+
+// shouldRetry reports whether an error class is retryable under the policy
+func shouldRetry(policy *interfaces.RetryPolicy, class string) bool {
+	if class == ErrorClassCancelled || class == ErrorClassPermanent {
+		return false
+	}
+
+	retryOn := policy.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+
+	for _, candidate := range retryOn {
+		if candidate == class || candidate == ErrorClassAny {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoffDelay returns the delay before the attempt following the given one
+func backoffDelay(policy *interfaces.RetryPolicy, attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		delay += delay * policy.Jitter * (2*rand.Float64() - 1)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// retryPolicyFromParameters reads a task's "retry" parameter, falling back to
+// the handler default when the task does not specify one
+func retryPolicyFromParameters(params map[string]interface{}, fallback *interfaces.RetryPolicy) (*interfaces.RetryPolicy, error) {
+	raw, ok := params["retry"]
+	if !ok {
+		if fallback == nil {
+			return &interfaces.RetryPolicy{MaxAttempts: 1}, nil
+		}
+		return fallback, nil
+	}
+
+	var policy *interfaces.RetryPolicy
+	if fallback != nil {
+		copied := *fallback
+		policy = &copied
+	} else {
+		policy = DefaultRetryPolicy(1)
+	}
+
+	switch value := raw.(type) {
+	case float64:
+		policy.MaxAttempts = int(value)
+	case int:
+		policy.MaxAttempts = value
+	case map[string]interface{}:
+		if v, ok := value["max_attempts"]; ok {
+			n, ok := numberParam(v)
+			if !ok {
+				return nil, fmt.Errorf("invalid retry.max_attempts: %v", v)
+			}
+			policy.MaxAttempts = int(n)
+		}
+		if v, ok := value["initial_backoff"]; ok {
+			d, err := durationParam(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry.initial_backoff: %w", err)
+			}
+			policy.InitialBackoff = d
+		}
+		if v, ok := value["max_backoff"]; ok {
+			d, err := durationParam(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry.max_backoff: %w", err)
+			}
+			policy.MaxBackoff = d
+		}
+		if v, ok := value["multiplier"]; ok {
+			n, ok := numberParam(v)
+			if !ok {
+				return nil, fmt.Errorf("invalid retry.multiplier: %v", v)
+			}
+			policy.Multiplier = n
+		}
+		if v, ok := value["jitter"]; ok {
+			n, ok := numberParam(v)
+			if !ok || n < 0 || n > 1 {
+				return nil, fmt.Errorf("invalid retry.jitter: %v", v)
+			}
+			policy.Jitter = n
+		}
+		if v, ok := value["retry_on"]; ok {
+			classes, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid retry.retry_on: expected a list of error classes")
+			}
+			policy.RetryOn = make([]string, 0, len(classes))
+			for _, class := range classes {
+				name, ok := class.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid retry.retry_on entry: %v", class)
+				}
+				policy.RetryOn = append(policy.RetryOn, name)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid retry parameter: expected an object or attempt count")
+	}
+
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return policy, nil
+}
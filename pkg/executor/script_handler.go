@@ -1,87 +1,193 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/schema"
 )
 
+// ScriptConfig holds the sandbox limits applied to every script task
+type ScriptConfig struct {
+	// AllowedCommands lists the executables tasks may run; an entry of "*"
+	// allows any command
+	AllowedCommands []string
+	// MaxOutputBytes caps how much of stdout and stderr is kept (each)
+	MaxOutputBytes int
+	// CPUSeconds limits the CPU time of the process (0 disables the limit)
+	CPUSeconds int
+	// MemoryBytes limits the virtual memory of the process (0 disables the limit)
+	MemoryBytes int64
+	// Timeout bounds the wall-clock time of a single run (0 disables it)
+	Timeout time.Duration
+	// WorkingDirRoot confines commands to this directory: they run in it
+	// unless the task picks a working_dir below it, and arguments naming
+	// paths outside it are rejected. When empty, commands run in the agent's
+	// working directory with unchecked arguments, so the sandbox only limits
+	// which binaries run. Inline scripts and the legacy "run:" form are never
+	// checked; they need their interpreter (sh by default) in AllowedCommands,
+	// which grants scripts everything the interpreter can do.
+	WorkingDirRoot string
+}
+
+// DefaultScriptConfig returns conservative sandbox limits. The default
+// commands only read their inputs and write to standard output, and the
+// files they may read are confined to the results directory; sort, uniq and
+// date can write files, run other programs or change the system clock and are
+// left out, and so is sh, which disables inline scripts.
+func DefaultScriptConfig() ScriptConfig {
+	return ScriptConfig{
+		AllowedCommands: []string{"echo", "cat", "ls", "grep", "head", "tail", "wc", "jq"},
+		MaxOutputBytes:  1 << 20,
+		CPUSeconds:      60,
+		MemoryBytes:     512 << 20,
+		Timeout:         2 * time.Minute,
+		WorkingDirRoot:  ArtifactsDir,
+	}
+}
+
 // ScriptTaskHandler handles script execution tasks
 type ScriptTaskHandler struct {
+	config ScriptConfig
 	logger interfaces.Logger
 }
 
-// NewScriptTaskHandler creates a new script task handler
+// NewScriptTaskHandler creates a new script task handler with the default sandbox
 func NewScriptTaskHandler(logger interfaces.Logger) *ScriptTaskHandler {
+	return NewScriptTaskHandlerWithConfig(DefaultScriptConfig(), logger)
+}
+
+// NewScriptTaskHandlerWithConfig creates a new script task handler with specific sandbox limits
+func NewScriptTaskHandlerWithConfig(config ScriptConfig, logger interfaces.Logger) *ScriptTaskHandler {
 	return &ScriptTaskHandler{
+		config: config,
 		logger: logger,
 	}
 }
 
-// Handle executes a script task
+// scriptInvocation describes a single process to run
+type scriptInvocation struct {
+	command    string
+	args       []string
+	workingDir string
+	env        []string
+	stdin      string
+	cleanup    func()
+
+	// pathArgs are the arguments given by the task, checked against the
+	// working directory root
+	pathArgs []string
+}
+
+// Handle executes a script task. Tasks either name a command with arguments or
+// pass an inline script to an interpreter; the legacy "run: <command>" form in
+// the description is executed through sh, so it needs sh to be allowed.
 func (h *ScriptTaskHandler) Handle(ctx context.Context, task *interfaces.Task) error {
-	h.logger.Info("Executing script task", map[string]interface{}{
+	h.logger.WithFields(map[string]interface{}{
 		"task_id":     task.ID,
 		"description": task.Description,
-	})
+	}).Info("Handling script task")
 
-	// For now, we'll simulate script execution by logging the task
-	// In a real implementation, you might execute shell commands or custom scripts
-	description := task.Description
+	invocation, err := h.buildInvocation(task)
+	if err != nil {
+		return Permanent(err)
+	}
+	defer invocation.cleanup()
 
-	// Simple script simulation based on description
-	if strings.Contains(strings.ToLower(description), "open") && strings.Contains(strings.ToLower(description), "search engine") {
-		h.logger.Info("Simulating opening search engine", map[string]interface{}{
-			"task_id": task.ID,
-			"action":  "open_search_engine",
-		})
+	if err := h.checkAllowed(invocation.command); err != nil {
+		return Permanent(err)
+	}
 
-		// Simulate opening a browser to a search engine
-		// In a real scenario, this might trigger a browser action
-		return nil
+	// Resolve the command against the agent's own PATH so neither the task's
+	// environment nor the limits wrapper decides which executable runs
+	path, err := resolveCommand(invocation.command)
+	if err != nil {
+		return Permanent(err)
 	}
 
-	// For other script tasks, we can try to execute them as shell commands
-	// This is a simplified implementation - in production you'd want more security
-	if strings.HasPrefix(description, "run:") {
-		command := strings.TrimPrefix(description, "run:")
-		command = strings.TrimSpace(command)
+	if err := h.confine(invocation); err != nil {
+		return Permanent(err)
+	}
 
-		h.logger.Info("Executing shell command", map[string]interface{}{
-			"task_id": task.ID,
-			"command": command,
-		})
+	if h.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.config.Timeout)
+		defer cancel()
+	}
 
-		cmd := exec.CommandContext(ctx, "sh", "-c", command)
-		output, err := cmd.CombinedOutput()
+	name, args := applyResourceLimits(path, invocation.args, h.config)
 
-		if err != nil {
-			h.logger.Error("Script execution failed", map[string]interface{}{
-				"task_id": task.ID,
-				"command": command,
-				"error":   err.Error(),
-				"output":  string(output),
-			})
-			return fmt.Errorf("script execution failed: %w", err)
-		}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = invocation.workingDir
+	cmd.Env = invocation.env
+	cmd.WaitDelay = time.Second
+	if invocation.stdin != "" {
+		cmd.Stdin = strings.NewReader(invocation.stdin)
+	}
 
-		h.logger.Info("Script executed successfully", map[string]interface{}{
-			"task_id": task.ID,
-			"command": command,
-			"output":  string(output),
-		})
+	stdout := &cappedBuffer{limit: h.config.MaxOutputBytes}
+	stderr := &cappedBuffer{limit: h.config.MaxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-		return nil
+	h.logger.WithFields(map[string]interface{}{
+		"task_id": task.ID,
+		"command": invocation.command,
+		"args":    invocation.args,
+	}).Info("Executing script")
+
+	startedAt := time.Now()
+	runErr := cmd.Run()
+
+	exitCode := 0
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
 	}
 
-	// For other script tasks, just log and mark as completed
-	h.logger.Info("Script task completed (simulated)", map[string]interface{}{
-		"task_id":     task.ID,
-		"description": description,
-	})
+	task.Result = map[string]interface{}{
+		"action":           "script",
+		"command":          invocation.command,
+		"args":             invocation.args,
+		"exit_code":        exitCode,
+		"stdout":           stdout.String(),
+		"stderr":           stderr.String(),
+		"stdout_truncated": stdout.truncated,
+		"stderr_truncated": stderr.truncated,
+		"duration":         time.Since(startedAt).String(),
+	}
+
+	if runErr != nil {
+		h.logger.WithFields(map[string]interface{}{
+			"task_id":   task.ID,
+			"command":   invocation.command,
+			"exit_code": exitCode,
+			"error":     runErr.Error(),
+		}).Error("Script execution failed")
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("script %s interrupted: %w", invocation.command, ctx.Err())
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("script %s exited with status %d: %s", invocation.command, exitCode, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("script execution failed: %w", runErr)
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"task_id":      task.ID,
+		"command":      invocation.command,
+		"stdout_bytes": stdout.Len(),
+	}).Info("Script executed successfully")
 
 	return nil
 }
@@ -90,3 +196,271 @@ func (h *ScriptTaskHandler) Handle(ctx context.Context, task *interfaces.Task) e
 func (h *ScriptTaskHandler) CanHandle(taskType string) bool {
 	return taskType == "script"
 }
+
+// scriptParameters is the parameter schema of script tasks
+var scriptParameters = schema.MustParse(`{
+	"type": "object",
+	"properties": {
+		"command": {"type": "string", "description": "Executable to run; must be in the allowed command list"},
+		"args": {"type": "array", "items": {"type": ["string", "number", "boolean"]}, "description": "Arguments passed to the command or script; paths must stay inside the results directory"},
+		"interpreter": {"type": "string", "description": "Interpreter for an inline script; defaults to sh"},
+		"script": {"type": "string", "description": "Inline script source run by the interpreter"},
+		"working_dir": {"type": "string", "description": "Existing directory to run in, inside the results directory (the default)"},
+		"env": {"type": "object", "additionalProperties": {"type": ["string", "number", "boolean"]}, "description": "Extra environment variables; PATH, IFS, BASH_ENV, LD_* and DYLD_* cannot be set"},
+		"stdin": {"description": "Data written to standard input"}
+	},
+	"additionalProperties": false
+}`)
+
+// Describe returns the actions and parameter schema of script tasks
+func (h *ScriptTaskHandler) Describe() interfaces.HandlerDescription {
+	return interfaces.HandlerDescription{
+		TaskType:    "script",
+		Description: fmt.Sprintf("Run a sandboxed command or inline script; allowed commands: %s", strings.Join(h.config.AllowedCommands, ", ")),
+		Actions:     []string{"command", "script"},
+		Parameters:  scriptParameters,
+	}
+}
+
+// buildInvocation turns the task parameters into a process invocation
+func (h *ScriptTaskHandler) buildInvocation(task *interfaces.Task) (*scriptInvocation, error) {
+	params := task.Parameters
+	invocation := &scriptInvocation{cleanup: func() {}}
+
+	command, _ := params["command"].(string)
+	interpreter, _ := params["interpreter"].(string)
+	script, _ := params["script"].(string)
+
+	args, err := stringListParam(params["args"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'args' parameter: %w", err)
+	}
+
+	switch {
+	case command != "":
+		invocation.command = command
+		invocation.args = args
+		invocation.pathArgs = args
+	case script != "":
+		if interpreter == "" {
+			interpreter = "sh"
+		}
+
+		// Write the script to a file so every interpreter can run it the same way
+		file, err := os.CreateTemp("", "agent-script-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create script file: %w", err)
+		}
+		if _, err := file.WriteString(script); err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return nil, fmt.Errorf("failed to write script file: %w", err)
+		}
+		file.Close()
+
+		invocation.command = interpreter
+		invocation.args = append([]string{file.Name()}, args...)
+		invocation.pathArgs = args
+		invocation.cleanup = func() { os.Remove(file.Name()) }
+	case strings.HasPrefix(task.Description, "run:"):
+		invocation.command = "sh"
+		invocation.args = []string{"-c", strings.TrimSpace(strings.TrimPrefix(task.Description, "run:"))}
+	default:
+		return nil, fmt.Errorf("script task %s has no 'command' or 'script' parameter", task.ID)
+	}
+
+	if dir, ok := params["working_dir"].(string); ok && dir != "" {
+		if h.config.WorkingDirRoot == "" {
+			invocation.cleanup()
+			return nil, fmt.Errorf("script tasks cannot set a working directory")
+		}
+		resolved, err := resolveWithin(h.config.WorkingDirRoot, dir)
+		if err != nil {
+			invocation.cleanup()
+			return nil, fmt.Errorf("invalid working directory: %w", err)
+		}
+		if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+			invocation.cleanup()
+			return nil, fmt.Errorf("working directory %q is not a directory", dir)
+		}
+		invocation.workingDir = resolved
+	}
+
+	invocation.env = baseScriptEnv()
+	if env, ok := params["env"].(map[string]interface{}); ok {
+		for key, value := range env {
+			if isProtectedEnv(key) {
+				invocation.cleanup()
+				return nil, fmt.Errorf("environment variable %s cannot be set by script tasks", key)
+			}
+			invocation.env = append(invocation.env, key+"="+stringifyParam(value))
+		}
+	}
+
+	if stdin, ok := params["stdin"]; ok && stdin != nil {
+		invocation.stdin = stringifyParam(stdin)
+	}
+
+	return invocation, nil
+}
+
+// confine runs the invocation in the working directory root unless the task
+// chose a directory below it, and rejects arguments naming paths outside the
+// root. Without a root nothing is confined.
+func (h *ScriptTaskHandler) confine(invocation *scriptInvocation) error {
+	root := h.config.WorkingDirRoot
+	if root == "" {
+		return nil
+	}
+
+	if invocation.workingDir == "" {
+		if err := os.MkdirAll(root, 0755); err != nil {
+			return fmt.Errorf("failed to create working directory %s: %w", root, err)
+		}
+		dir, err := resolveWithin(root, root)
+		if err != nil {
+			return err
+		}
+		invocation.workingDir = dir
+	}
+
+	for _, arg := range invocation.pathArgs {
+		if err := checkPathArg(root, invocation.workingDir, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPathArg rejects an argument naming a path outside root, resolving it
+// against the working directory like the command will. Options may only carry
+// a path after "=", as in --file=data.json.
+func checkPathArg(root, workingDir, arg string) error {
+	path := arg
+	if strings.HasPrefix(arg, "-") {
+		_, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			if strings.ContainsRune(arg, filepath.Separator) || strings.Contains(arg, "..") {
+				return fmt.Errorf("argument %q: pass paths as separate arguments or as --option=path", arg)
+			}
+			return nil
+		}
+		path = value
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	if err := checkWithin(root, path); err != nil {
+		return fmt.Errorf("argument %q: %w", arg, err)
+	}
+	return nil
+}
+
+// checkAllowed verifies a command against the allowlist. Bare names are looked
+// up on PATH; commands given as paths must be listed with their exact path.
+func (h *ScriptTaskHandler) checkAllowed(command string) error {
+	isPath := strings.ContainsRune(command, filepath.Separator)
+	for _, allowed := range h.config.AllowedCommands {
+		if allowed == "*" || allowed == command {
+			return nil
+		}
+		if !isPath && allowed == filepath.Base(command) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("command %q is not in the allowed command list", command)
+}
+
+// resolveCommand returns the absolute path of the executable a command names,
+// looking bare names up on the agent's PATH
+func resolveCommand(command string) (string, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", fmt.Errorf("command %q not found: %w", command, err)
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve command %q: %w", command, err)
+	}
+	return path, nil
+}
+
+// isProtectedEnv reports whether a task-supplied environment variable could
+// change which programs run or what the shell and dynamic loader execute
+func isProtectedEnv(key string) bool {
+	switch key {
+	case "PATH", "IFS", "BASH_ENV":
+		return true
+	}
+	return strings.HasPrefix(key, "LD_") || strings.HasPrefix(key, "DYLD_")
+}
+
+// baseScriptEnv returns the minimal environment scripts inherit from the agent
+func baseScriptEnv() []string {
+	// A non-nil slice keeps exec from inheriting the full agent environment
+	env := []string{}
+	for _, key := range []string{"PATH", "HOME", "LANG", "TMPDIR"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+// stringListParam converts a list parameter into strings
+func stringListParam(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			list[i] = stringifyParam(item)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+}
+
+// cappedBuffer keeps at most limit bytes and records whether output was dropped.
+// The buffer is a named field so io.Copy cannot bypass Write via ReadFrom.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write stores as much of p as fits; it never fails so the process is not blocked
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+
+	remaining := b.limit - b.buf.Len()
+	if remaining <= 0 {
+		b.truncated = b.truncated || len(p) > 0
+		return len(p), nil
+	}
+	if len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+
+	return b.buf.Write(p)
+}
+
+// String returns the captured output
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// Len returns the number of captured bytes
+func (b *cappedBuffer) Len() int {
+	return b.buf.Len()
+}
@@ -0,0 +1,190 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptTaskHandlerCapturesOutput(t *testing.T) {
+	config := DefaultScriptConfig()
+	config.WorkingDirRoot = t.TempDir()
+	handler := NewScriptTaskHandlerWithConfig(config, logger.NewLogrusLogger("error"))
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "script",
+		Parameters: map[string]interface{}{
+			"command": "cat",
+			"stdin":   "hello from stdin",
+		},
+	}
+
+	require.NoError(t, handler.Handle(context.Background(), task))
+
+	result := task.Result.(map[string]interface{})
+	assert.Equal(t, 0, result["exit_code"])
+	assert.Equal(t, "hello from stdin", result["stdout"])
+}
+
+func TestScriptTaskHandlerRejectsDisallowedCommands(t *testing.T) {
+	handler := NewScriptTaskHandler(logger.NewLogrusLogger("error"))
+
+	for _, command := range []string{"rm", "/tmp/bin/echo"} {
+		task := &interfaces.Task{
+			ID:         "t1",
+			Type:       "script",
+			Parameters: map[string]interface{}{"command": command},
+		}
+
+		err := handler.Handle(context.Background(), task)
+		require.Error(t, err)
+		assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	}
+}
+
+func TestScriptTaskHandlerRejectsShellByDefault(t *testing.T) {
+	handler := NewScriptTaskHandler(logger.NewLogrusLogger("error"))
+
+	for _, task := range []*interfaces.Task{
+		{ID: "t1", Type: "script", Description: "run: echo hi"},
+		{ID: "t2", Type: "script", Parameters: map[string]interface{}{"script": "echo hi"}},
+	} {
+		err := handler.Handle(context.Background(), task)
+		assert.ErrorContains(t, err, "not in the allowed command list")
+		assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	}
+}
+
+func TestScriptTaskHandlerRunsInterpreterScripts(t *testing.T) {
+	config := DefaultScriptConfig()
+	config.AllowedCommands = []string{"sh"}
+	config.MaxOutputBytes = 8
+	config.WorkingDirRoot = t.TempDir()
+	handler := NewScriptTaskHandlerWithConfig(config, logger.NewLogrusLogger("error"))
+
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "script",
+		Parameters: map[string]interface{}{
+			"interpreter": "sh",
+			"script":      "echo \"$GREETING, $1\"; echo oops >&2; exit 3",
+			"args":        []interface{}{"world"},
+			"env":         map[string]interface{}{"GREETING": "hello"},
+		},
+	}
+
+	err := handler.Handle(context.Background(), task)
+	require.Error(t, err)
+
+	result := task.Result.(map[string]interface{})
+	assert.Equal(t, 3, result["exit_code"])
+	assert.Equal(t, "hello, w", result["stdout"])
+	assert.Equal(t, true, result["stdout_truncated"])
+	assert.Equal(t, "oops\n", result["stderr"])
+}
+
+func TestScriptTaskHandlerRejectsProtectedEnvironment(t *testing.T) {
+	handler := NewScriptTaskHandler(logger.NewLogrusLogger("error"))
+
+	for _, key := range []string{"PATH", "LD_PRELOAD", "DYLD_INSERT_LIBRARIES", "IFS", "BASH_ENV"} {
+		task := &interfaces.Task{
+			ID:   "t1",
+			Type: "script",
+			Parameters: map[string]interface{}{
+				"command": "cat",
+				"env":     map[string]interface{}{key: "/tmp/evil"},
+			},
+		}
+
+		err := handler.Handle(context.Background(), task)
+		assert.EqualError(t, err, "environment variable "+key+" cannot be set by script tasks")
+		assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	}
+}
+
+func TestResolveCommandUsesAgentPath(t *testing.T) {
+	path, err := resolveCommand("cat")
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(path))
+	assert.Equal(t, "cat", filepath.Base(path))
+
+	_, err = resolveCommand("no-such-command-anywhere")
+	assert.Error(t, err)
+}
+
+func TestScriptTaskHandlerConfinesWorkingDir(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "run"), 0755))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	config := DefaultScriptConfig()
+	config.WorkingDirRoot = root
+	handler := NewScriptTaskHandlerWithConfig(config, logger.NewLogrusLogger("error"))
+
+	run := func(dir string) error {
+		task := &interfaces.Task{
+			ID:         "t1",
+			Type:       "script",
+			Parameters: map[string]interface{}{"command": "ls", "working_dir": dir},
+		}
+		return handler.Handle(context.Background(), task)
+	}
+
+	assert.NoError(t, run(filepath.Join(root, "run")))
+	for _, dir := range []string{filepath.Join(root, ".."), outside, filepath.Join(root, "escape"), "/"} {
+		err := run(dir)
+		assert.ErrorContains(t, err, "is outside", dir)
+		assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	}
+
+	config.WorkingDirRoot = ""
+	handler = NewScriptTaskHandlerWithConfig(config, logger.NewLogrusLogger("error"))
+	assert.EqualError(t, run(filepath.Join(root, "run")), "script tasks cannot set a working directory")
+}
+
+func TestScriptTaskHandlerConfinesPathArguments(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "data.txt"), []byte("inside"), 0644))
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	require.NoError(t, os.WriteFile(secret, []byte("outside"), 0644))
+	require.NoError(t, os.Symlink(secret, filepath.Join(root, "escape")))
+
+	config := DefaultScriptConfig()
+	config.WorkingDirRoot = root
+	handler := NewScriptTaskHandlerWithConfig(config, logger.NewLogrusLogger("error"))
+
+	run := func(command string, args ...interface{}) (*interfaces.Task, error) {
+		task := &interfaces.Task{
+			ID:         "t1",
+			Type:       "script",
+			Parameters: map[string]interface{}{"command": command, "args": args},
+		}
+		return task, handler.Handle(context.Background(), task)
+	}
+
+	task, err := run("cat", "data.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "inside", task.Result.(map[string]interface{})["stdout"])
+
+	for _, args := range [][]interface{}{
+		{secret},
+		{"../" + filepath.Base(outside) + "/secret.txt"},
+		{"escape"},
+		{"-n", "--file=" + secret},
+	} {
+		_, err := run("cat", args...)
+		assert.ErrorContains(t, err, "is outside", args)
+		assert.Equal(t, ErrorClassPermanent, ClassifyError(err))
+	}
+
+	_, err = run("grep", "-f"+secret, "data.txt")
+	assert.ErrorContains(t, err, "pass paths as separate arguments")
+}
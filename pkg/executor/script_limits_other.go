@@ -0,0 +1,8 @@
+//go:build !unix
+
+package executor
+
+// applyResourceLimits is a no-op on platforms without POSIX rlimits
+func applyResourceLimits(command string, args []string, config ScriptConfig) (string, []string) {
+	return command, args
+}
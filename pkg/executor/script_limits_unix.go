@@ -0,0 +1,24 @@
+//go:build unix
+
+package executor
+
+import "fmt"
+
+// applyResourceLimits wraps the command in a shell that sets CPU and memory
+// rlimits before exec'ing it, so the limits apply only to the child process
+func applyResourceLimits(command string, args []string, config ScriptConfig) (string, []string) {
+	if config.CPUSeconds <= 0 && config.MemoryBytes <= 0 {
+		return command, args
+	}
+
+	script := ""
+	if config.CPUSeconds > 0 {
+		script += fmt.Sprintf("ulimit -t %d || exit 126; ", config.CPUSeconds)
+	}
+	if config.MemoryBytes > 0 {
+		script += fmt.Sprintf("ulimit -v %d || exit 126; ", config.MemoryBytes/1024)
+	}
+	script += `exec "$@"`
+
+	return "/bin/sh", append([]string{"-c", script, "sh", command}, args...)
+}
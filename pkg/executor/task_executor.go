@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -12,30 +13,52 @@ import (
 // TaskExecutorImpl implements the TaskExecutor interface
 type TaskExecutorImpl struct {
 	handlers     map[string]interfaces.TaskHandler
+	configs      map[string]interfaces.HandlerConfig
 	memory       interfaces.MemoryStore
 	eventBus     interfaces.EventBus
 	logger       interfaces.Logger
 	mutex        sync.RWMutex
 	runningTasks map[string]context.CancelFunc
+	taskRuns     map[string]*taskRun
+}
+
+// errAttemptAbandoned marks an attempt whose handler was still running when
+// its deadline passed. The handler may still be working on the task, so the
+// attempt is never retried.
+var errAttemptAbandoned = errors.New("task did not finish before its deadline")
+
+// taskRun tracks a single execution of a task until it reaches a terminal state
+type taskRun struct {
+	task *interfaces.Task
+	err  error
+	done chan struct{}
 }
 
 // NewTaskExecutor creates a new task executor
 func NewTaskExecutor(memory interfaces.MemoryStore, eventBus interfaces.EventBus, logger interfaces.Logger) *TaskExecutorImpl {
 	return &TaskExecutorImpl{
 		handlers:     make(map[string]interfaces.TaskHandler),
+		configs:      make(map[string]interfaces.HandlerConfig),
 		memory:       memory,
 		eventBus:     eventBus,
 		logger:       logger,
 		runningTasks: make(map[string]context.CancelFunc),
+		taskRuns:     make(map[string]*taskRun),
 	}
 }
 
 // RegisterHandler registers a handler for a specific task type
-func (e *TaskExecutorImpl) RegisterHandler(taskType string, handler interfaces.TaskHandler) {
+func (e *TaskExecutorImpl) RegisterHandler(taskType string, handler interfaces.TaskHandler, opts ...interfaces.HandlerOption) {
+	var config interfaces.HandlerConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
 	e.handlers[taskType] = handler
+	e.configs[taskType] = config
 	e.logger.WithField("task_type", taskType).Info("Registered task handler")
 }
 
@@ -50,12 +73,34 @@ func (e *TaskExecutorImpl) ExecuteTask(ctx context.Context, task *interfaces.Tas
 	// Check if handler exists
 	e.mutex.RLock()
 	handler, exists := e.handlers[task.Type]
+	config := e.configs[task.Type]
 	e.mutex.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("no handler registered for task type: %s", task.Type)
 	}
 
+	policy, err := retryPolicyFromParameters(task.Parameters, config.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid retry policy for task %s: %w", task.ID, err)
+	}
+
+	timeout, err := taskTimeoutFromParameters(task.Parameters, config.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout for task %s: %w", task.ID, err)
+	}
+
+	// Feed results of completed dependencies into the parameters the handler
+	// sees; the task keeps its template expressions
+	params, err := e.resolveParameters(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to resolve parameters: %w", err)
+	}
+
+	if err := validateParameters(handler, task.Type, task.ID, params); err != nil {
+		return err
+	}
+
 	// Update task status to running
 	task.Status = interfaces.TaskStatusRunning
 	task.UpdatedAt = time.Now()
@@ -72,24 +117,58 @@ func (e *TaskExecutorImpl) ExecuteTask(ctx context.Context, task *interfaces.Tas
 
 	// Create cancellable context for the task
 	taskCtx, cancel := context.WithCancel(ctx)
+	run := &taskRun{
+		task: task,
+		done: make(chan struct{}),
+	}
 
 	e.mutex.Lock()
 	e.runningTasks[task.ID] = cancel
+	e.taskRuns[task.ID] = run
 	e.mutex.Unlock()
 
 	// Execute task in goroutine
 	go func() {
+		defer close(run.done)
 		defer func() {
 			e.mutex.Lock()
 			delete(e.runningTasks, task.ID)
 			e.mutex.Unlock()
+			cancel()
 		}()
 
-		// Execute the task
-		err := handler.Handle(taskCtx, task)
+		// Execute the task, retrying failed attempts according to the policy
+		err := e.handleWithRetry(taskCtx, handler, task, params, policy, timeout)
+		run.err = err
 
 		// Update task status based on result
-		if err != nil {
+		switch {
+		case err != nil && errors.Is(err, context.DeadlineExceeded):
+			task.Status = interfaces.TaskStatusTimedOut
+			task.Error = err.Error()
+			e.logger.WithFields(map[string]interface{}{
+				"task_id": task.ID,
+				"error":   err.Error(),
+			}).Error("Task execution timed out")
+
+			// Publish task timed out event
+			e.eventBus.Publish(context.Background(), "task.timed_out", map[string]interface{}{
+				"task_id": task.ID,
+				"error":   err.Error(),
+			})
+		case err != nil && errors.Is(taskCtx.Err(), context.Canceled):
+			task.Status = interfaces.TaskStatusCancelled
+			task.Error = err.Error()
+			e.logger.WithField("task_id", task.ID).Warn("Task execution cancelled")
+
+			// CancelTask publishes its own event; only report cancellations
+			// caused by the parent context going away
+			if ctx.Err() != nil {
+				e.eventBus.Publish(context.Background(), "task.cancelled", map[string]interface{}{
+					"task_id": task.ID,
+				})
+			}
+		case err != nil:
 			task.Status = interfaces.TaskStatusFailed
 			task.Error = err.Error()
 			e.logger.WithFields(map[string]interface{}{
@@ -102,7 +181,7 @@ func (e *TaskExecutorImpl) ExecuteTask(ctx context.Context, task *interfaces.Tas
 				"task_id": task.ID,
 				"error":   err.Error(),
 			})
-		} else {
+		default:
 			task.Status = interfaces.TaskStatusCompleted
 			e.logger.WithField("task_id", task.ID).Info("Task execution completed")
 
@@ -124,6 +203,156 @@ func (e *TaskExecutorImpl) ExecuteTask(ctx context.Context, task *interfaces.Tas
 	return nil
 }
 
+// handleWithRetry runs the handler until it succeeds, fails with a
+// non-retryable error or exhausts the policy's attempts
+func (e *TaskExecutorImpl) handleWithRetry(ctx context.Context, handler interfaces.TaskHandler, task *interfaces.Task, params map[string]interface{}, policy *interfaces.RetryPolicy, timeout time.Duration) error {
+	for attempt := 1; ; attempt++ {
+		startedAt := time.Now()
+		err := e.runAttempt(ctx, handler, task, params, timeout)
+
+		record := interfaces.TaskAttempt{
+			Number:     attempt,
+			StartedAt:  startedAt,
+			FinishedAt: time.Now(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+			record.ErrorClass = ClassifyError(err)
+		}
+		task.Attempts = append(task.Attempts, record)
+
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, errAttemptAbandoned) {
+			e.logger.WithFields(map[string]interface{}{
+				"task_id": task.ID,
+				"attempt": attempt,
+			}).Warn("Not retrying abandoned task attempt")
+			return err
+		}
+
+		if attempt >= policy.MaxAttempts || !shouldRetry(policy, record.ErrorClass) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoffDelay(policy, attempt)
+
+		e.logger.WithFields(map[string]interface{}{
+			"task_id":     task.ID,
+			"attempt":     attempt,
+			"error":       err.Error(),
+			"error_class": record.ErrorClass,
+			"delay":       delay.String(),
+		}).Warn("Task attempt failed, retrying")
+
+		// Publish task retrying event
+		e.eventBus.Publish(ctx, "task.retrying", map[string]interface{}{
+			"task_id":      task.ID,
+			"attempt":      attempt,
+			"max_attempts": policy.MaxAttempts,
+			"error":        err.Error(),
+			"error_class":  record.ErrorClass,
+			"delay":        delay.String(),
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// runAttempt runs a single handler attempt, bounded by the task timeout, which
+// applies to each attempt rather than the whole task, and the deadline of the
+// parent context. Handlers that ignore their context are abandoned once the
+// deadline passes so a hung handler cannot block the plan; such an attempt
+// ends the task with errAttemptAbandoned.
+//
+// The handler works on a copy of the task holding the resolved parameters, so
+// an abandoned handler never touches the task. Only the result of a
+// successful attempt is copied back; a failed attempt clears the result so no
+// partial output of it is seen by later attempts or dependent tasks.
+func (e *TaskExecutorImpl) runAttempt(ctx context.Context, handler interfaces.TaskHandler, task *interfaces.Task, params map[string]interface{}, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	attempt := attemptTask(task, params)
+	result := make(chan error, 1)
+	go func() {
+		result <- handler.Handle(ctx, attempt)
+	}()
+
+	select {
+	case err := <-result:
+		task.Result = nil
+		if err == nil {
+			task.Result = attempt.Result
+		}
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			e.logger.WithFields(map[string]interface{}{
+				"task_id": task.ID,
+				"timeout": timeout.String(),
+			}).Warn("Abandoning task attempt after deadline")
+			return fmt.Errorf("%w: %w", errAttemptAbandoned, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+// attemptTask returns the copy of task a handler attempt works on, without a
+// result and with its own copy of the resolved parameters for handlers that
+// fill in defaults
+func attemptTask(task *interfaces.Task, params map[string]interface{}) *interfaces.Task {
+	attempt := *task
+	attempt.Result = nil
+	attempt.Parameters = make(map[string]interface{}, len(params))
+	for key, value := range params {
+		attempt.Parameters[key] = value
+	}
+	return &attempt
+}
+
+// WaitTask blocks until the task reaches a terminal state and returns the final
+// task together with the error its handler returned. The run is released once
+// collected, so each execution is waited for once.
+func (e *TaskExecutorImpl) WaitTask(ctx context.Context, taskID string) (*interfaces.Task, error) {
+	e.mutex.RLock()
+	run, exists := e.taskRuns[taskID]
+	e.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("task was never started: %s", taskID)
+	}
+
+	select {
+	case <-run.done:
+		// A rerun of the task may have replaced the entry in the meantime
+		e.mutex.Lock()
+		if e.taskRuns[taskID] == run {
+			delete(e.taskRuns, taskID)
+		}
+		e.mutex.Unlock()
+
+		if run.err != nil {
+			return run.task, fmt.Errorf("task %s %s: %w", taskID, run.task.Status, run.err)
+		}
+		return run.task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // GetTaskStatus returns the current status of a task
 func (e *TaskExecutorImpl) GetTaskStatus(ctx context.Context, taskID string) (interfaces.TaskStatus, error) {
 	data, err := e.memory.Retrieve(ctx, "task:"+taskID)
@@ -149,27 +378,10 @@ func (e *TaskExecutorImpl) CancelTask(ctx context.Context, taskID string) error
 		return fmt.Errorf("task not running: %s", taskID)
 	}
 
-	// Cancel the task context
+	// Cancel the task context; the execution goroutine records the
+	// cancelled status once the handler returns
 	cancel()
 
-	// Update task status
-	data, err := e.memory.Retrieve(ctx, "task:"+taskID)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve task: %w", err)
-	}
-
-	task, ok := data.(*interfaces.Task)
-	if !ok {
-		return fmt.Errorf("invalid task data in memory")
-	}
-
-	task.Status = interfaces.TaskStatusCancelled
-	task.UpdatedAt = time.Now()
-
-	if err := e.memory.Store(ctx, "task:"+taskID, task); err != nil {
-		e.logger.WithField("error", err).Warn("Failed to store cancelled task status")
-	}
-
 	// Publish task cancelled event
 	e.eventBus.Publish(ctx, "task.cancelled", map[string]interface{}{
 		"task_id": taskID,
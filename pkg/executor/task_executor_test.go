@@ -0,0 +1,323 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/eventbus"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcHandler adapts a function to the TaskHandler interface
+type funcHandler func(ctx context.Context, task *interfaces.Task) error
+
+func (h funcHandler) Handle(ctx context.Context, task *interfaces.Task) error {
+	return h(ctx, task)
+}
+
+func (h funcHandler) CanHandle(taskType string) bool {
+	return true
+}
+
+func newTestExecutor() *TaskExecutorImpl {
+	log := logger.NewLogrusLogger("error")
+	return NewTaskExecutor(memory.NewInMemoryStore(log), eventbus.NewInMemoryEventBus(log), log)
+}
+
+func TestWaitTaskReturnsCompletedTask(t *testing.T) {
+	executor := newTestExecutor()
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		time.Sleep(20 * time.Millisecond)
+		task.Result = "done"
+		return nil
+	}))
+
+	ctx := context.Background()
+	task := &interfaces.Task{ID: "t1", Type: "test"}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.TaskStatusCompleted, final.Status)
+	assert.Equal(t, "done", final.Result)
+
+	// Collected runs are released
+	executor.mutex.RLock()
+	assert.Empty(t, executor.taskRuns)
+	executor.mutex.RUnlock()
+}
+
+func TestWaitTaskReturnsHandlerError(t *testing.T) {
+	executor := newTestExecutor()
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		return fmt.Errorf("handler exploded")
+	}))
+
+	ctx := context.Background()
+	task := &interfaces.Task{ID: "t1", Type: "test"}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handler exploded")
+	assert.Equal(t, interfaces.TaskStatusFailed, final.Status)
+
+	status, err := executor.GetTaskStatus(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.TaskStatusFailed, status)
+}
+
+func TestWaitTaskReportsCancellation(t *testing.T) {
+	executor := newTestExecutor()
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	ctx := context.Background()
+	task := &interfaces.Task{ID: "t1", Type: "test"}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+	require.NoError(t, executor.CancelTask(ctx, "t1"))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.Error(t, err)
+	assert.Equal(t, interfaces.TaskStatusCancelled, final.Status)
+}
+
+func TestWaitTaskUnknownTask(t *testing.T) {
+	executor := newTestExecutor()
+
+	_, err := executor.WaitTask(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestExecuteTaskRetriesTransientFailures(t *testing.T) {
+	executor := newTestExecutor()
+	calls := 0
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		calls++
+		assert.Nil(t, task.Result, "attempt %d sees the result of a failed attempt", calls)
+		if calls < 3 {
+			task.Result = "partial"
+			return Transient(fmt.Errorf("flaky"))
+		}
+		task.Result = "done"
+		return nil
+	}))
+
+	ctx := context.Background()
+	retrying, err := executor.eventBus.Subscribe(ctx, "task.retrying")
+	require.NoError(t, err)
+
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "test",
+		Parameters: map[string]interface{}{
+			"retry": map[string]interface{}{
+				"max_attempts":    float64(3),
+				"initial_backoff": "1ms",
+			},
+		},
+	}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, interfaces.TaskStatusCompleted, final.Status)
+	require.Len(t, final.Attempts, 3)
+	assert.Equal(t, ErrorClassTransient, final.Attempts[0].ErrorClass)
+	assert.Empty(t, final.Attempts[2].Error)
+	assert.Equal(t, "done", final.Result)
+	assert.Len(t, retrying, 2)
+}
+
+func TestExecuteTaskDoesNotRetryPermanentFailures(t *testing.T) {
+	executor := newTestExecutor()
+	calls := 0
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		calls++
+		task.Result = "partial"
+		return Permanent(fmt.Errorf("bad input"))
+	}), WithRetryPolicy(&interfaces.RetryPolicy{MaxAttempts: 5, RetryOn: []string{ErrorClassAny}}))
+
+	ctx := context.Background()
+	task := &interfaces.Task{ID: "t1", Type: "test"}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, final.Result, "failed attempts leave no result")
+}
+
+func TestBackoffDelayIsCapped(t *testing.T) {
+	policy := &interfaces.RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, backoffDelay(policy, 1))
+	assert.Equal(t, 200*time.Millisecond, backoffDelay(policy, 2))
+	assert.Equal(t, 300*time.Millisecond, backoffDelay(policy, 5))
+}
+
+func TestExecuteTaskTimesOutHungHandler(t *testing.T) {
+	executor := newTestExecutor()
+	release := make(chan struct{})
+	defer close(release)
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		// Ignore the context entirely, like a hung browser wait
+		<-release
+		return nil
+	}), WithTimeout(time.Hour))
+
+	ctx := context.Background()
+	task := &interfaces.Task{
+		ID:         "t1",
+		Type:       "test",
+		Parameters: map[string]interface{}{"task_timeout": "20ms"},
+	}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, interfaces.TaskStatusTimedOut, final.Status)
+}
+
+func TestExecuteTaskDoesNotRetryAbandonedAttempts(t *testing.T) {
+	executor := newTestExecutor()
+	release := make(chan struct{})
+	defer close(release)
+	var calls atomic.Int32
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		calls.Add(1)
+		<-release
+		return nil
+	}), WithRetryPolicy(&interfaces.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryOn: []string{ErrorClassAny}}))
+
+	ctx := context.Background()
+	task := &interfaces.Task{
+		ID:         "t1",
+		Type:       "test",
+		Parameters: map[string]interface{}{"task_timeout": "20ms"},
+	}
+	require.NoError(t, executor.ExecuteTask(ctx, task))
+
+	final, err := executor.WaitTask(ctx, "t1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, interfaces.TaskStatusTimedOut, final.Status)
+	assert.Len(t, final.Attempts, 1)
+	assert.Equal(t, int32(1), calls.Load(), "the abandoned handler may still be running")
+}
+
+func TestExecuteTaskResolvesDependencyTemplates(t *testing.T) {
+	executor := newTestExecutor()
+	var seen map[string]interface{}
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		if task.ID == "analyze" {
+			seen = task.Parameters
+		}
+		if task.ID == "search" {
+			task.Result = map[string]interface{}{
+				"links": []string{"https://a.example", "https://b.example"},
+			}
+		}
+		return nil
+	}))
+
+	ctx := context.Background()
+	search := &interfaces.Task{ID: "search", Name: "find_links", Type: "test"}
+	require.NoError(t, executor.ExecuteTask(ctx, search))
+	_, err := executor.WaitTask(ctx, "search")
+	require.NoError(t, err)
+
+	analyze := &interfaces.Task{
+		ID:           "analyze",
+		Type:         "test",
+		Dependencies: []string{"search"},
+		Parameters: map[string]interface{}{
+			"links":  "{{ tasks.search.result.links }}",
+			"first":  "First: {{ tasks.search.result.links[0] }}",
+			"status": "{{ tasks.find_links.status }}",
+		},
+	}
+	require.NoError(t, executor.ExecuteTask(ctx, analyze))
+	_, err = executor.WaitTask(ctx, "analyze")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"https://a.example", "https://b.example"}, seen["links"])
+	assert.Equal(t, "First: https://a.example", seen["first"])
+	assert.Equal(t, "completed", seen["status"])
+
+	// The task keeps its templates for retries, replans and plan diffs
+	assert.Equal(t, "{{ tasks.search.result.links }}", analyze.Parameters["links"])
+}
+
+func TestExecuteTaskRejectsUnknownTemplateReference(t *testing.T) {
+	executor := newTestExecutor()
+	executor.RegisterHandler("test", funcHandler(func(ctx context.Context, task *interfaces.Task) error {
+		return nil
+	}))
+
+	task := &interfaces.Task{
+		ID:         "analyze",
+		Type:       "test",
+		Parameters: map[string]interface{}{"links": "{{ tasks.search.result.links }}"},
+	}
+
+	err := executor.ExecuteTask(context.Background(), task)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a dependency")
+}
+
+func TestExecuteTaskValidatesParametersAgainstHandlerSchema(t *testing.T) {
+	executor := newTestExecutor()
+	executor.RegisterHandler("api", NewAPITaskHandler(logger.NewLogrusLogger("error")))
+
+	ctx := context.Background()
+	task := &interfaces.Task{
+		ID:   "t1",
+		Type: "api",
+		Parameters: map[string]interface{}{
+			"endpoint":     "https://example.com",
+			"task_timeout": "1s",
+		},
+	}
+	err := executor.ExecuteTask(ctx, task)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "url: is required")
+	assert.Contains(t, err.Error(), "endpoint: is not a supported property")
+	assert.NotContains(t, err.Error(), "task_timeout")
+
+	descriptions := executor.DescribeHandlers()
+	require.Len(t, descriptions, 1)
+	assert.Equal(t, "api", descriptions[0].TaskType)
+	assert.NotNil(t, descriptions[0].Parameters)
+}
+
+func TestValidatePlannedParametersSkipsTemplates(t *testing.T) {
+	description := NewAPITaskHandler(logger.NewLogrusLogger("error")).Describe()
+
+	err := ValidatePlannedParameters(description, map[string]interface{}{
+		"url":   "https://example.com",
+		"query": "{{ tasks.search.result.query }}",
+		"retry": map[string]interface{}{"max_attempts": 2},
+	})
+	assert.NoError(t, err)
+
+	err = ValidatePlannedParameters(description, map[string]interface{}{
+		"url":     "{{ tasks.search.result.url }}",
+		"headers": "none",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "headers: expected object, got string", err.Error())
+}
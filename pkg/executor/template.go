@@ -0,0 +1,307 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// templateExpr matches parameter expressions such as {{ tasks.search.result.links }}
+var templateExpr = regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+
+// indexExpr matches bracketed indices such as links[0]
+var indexExpr = regexp.MustCompile(`\[(\d+)\]`)
+
+// resolveParameters replaces template expressions in the task parameters with
+// values taken from the task's completed dependencies
+func (e *TaskExecutorImpl) resolveParameters(ctx context.Context, task *interfaces.Task) (map[string]interface{}, error) {
+	if !containsTemplate(task.Parameters) {
+		return task.Parameters, nil
+	}
+
+	deps := make(map[string]*interfaces.Task, len(task.Dependencies))
+	for _, depID := range task.Dependencies {
+		data, err := e.memory.Retrieve(ctx, "task:"+depID)
+		if err != nil {
+			continue
+		}
+		if dep, ok := data.(*interfaces.Task); ok {
+			deps[dep.ID] = dep
+		}
+	}
+
+	resolver := &templateResolver{task: task, deps: deps}
+	resolved, err := resolver.resolve(task.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved.(map[string]interface{}), nil
+}
+
+// containsTemplate reports whether any string inside value holds an expression
+func containsTemplate(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return templateExpr.MatchString(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsTemplate(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if containsTemplate(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TemplateReferences returns the tasks named by the parameter expressions in
+// params, such as "search" for {{ tasks.search.result.links }}, in order of
+// the sorted parameter keys. Malformed expressions are left for resolution
+// to report.
+func TemplateReferences(params map[string]interface{}) []string {
+	var refs []string
+	collectReferences(params, &refs)
+	return refs
+}
+
+// collectReferences appends the task references of the expressions inside value
+func collectReferences(value interface{}, refs *[]string) {
+	switch v := value.(type) {
+	case string:
+		for _, match := range templateExpr.FindAllStringSubmatch(v, -1) {
+			if parts := splitPath(match[1]); len(parts) >= 2 && parts[0] == "tasks" {
+				*refs = append(*refs, parts[1])
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collectReferences(v[key], refs)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectReferences(item, refs)
+		}
+	}
+}
+
+// templateResolver evaluates expressions against a task's dependencies
+type templateResolver struct {
+	task *interfaces.Task
+	deps map[string]*interfaces.Task
+}
+
+// resolve walks a parameter value and substitutes every expression it contains
+func (r *templateResolver) resolve(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = out
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			out, err := r.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = out
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveString substitutes the expressions in a string. A string consisting of
+// a single expression is replaced by the referenced value itself so lists and
+// objects keep their type; otherwise values are interpolated as text.
+func (r *templateResolver) resolveString(s string) (interface{}, error) {
+	matches := templateExpr.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return r.evaluate(s[matches[0][2]:matches[0][3]])
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		builder.WriteString(s[last:match[0]])
+
+		value, err := r.evaluate(s[match[2]:match[3]])
+		if err != nil {
+			return nil, err
+		}
+
+		switch v := value.(type) {
+		case string:
+			builder.WriteString(v)
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render template value: %w", err)
+			}
+			builder.Write(encoded)
+		}
+
+		last = match[1]
+	}
+	builder.WriteString(s[last:])
+
+	return builder.String(), nil
+}
+
+// evaluate resolves a single expression of the form tasks.<ref>.<field>[.<path>...]
+func (r *templateResolver) evaluate(expr string) (interface{}, error) {
+	parts := splitPath(expr)
+	if len(parts) < 3 || parts[0] != "tasks" {
+		return nil, fmt.Errorf("task %s: invalid parameter expression %q, expected tasks.<ref>.result[.<path>]", r.task.ID, expr)
+	}
+
+	ref := parts[1]
+	dep := r.lookupDependency(ref)
+	if dep == nil {
+		return nil, fmt.Errorf("task %s: expression %q references %q, which is not a dependency of this task", r.task.ID, expr, ref)
+	}
+
+	if dep.Status != interfaces.TaskStatusCompleted {
+		return nil, fmt.Errorf("task %s: expression %q references task %s, which has status %s", r.task.ID, expr, ref, dep.Status)
+	}
+
+	var root interface{}
+	switch parts[2] {
+	case "result":
+		root = dep.Result
+	case "status":
+		root = string(dep.Status)
+	case "parameters":
+		root = dep.Parameters
+	default:
+		return nil, fmt.Errorf("task %s: expression %q uses unknown field %q (expected result, status or parameters)", r.task.ID, expr, parts[2])
+	}
+
+	value, err := lookupPath(root, parts[3:])
+	if err != nil {
+		return nil, fmt.Errorf("task %s: expression %q: %w", r.task.ID, expr, err)
+	}
+
+	return value, nil
+}
+
+// lookupDependency finds a dependency by ID or by its name in the plan
+func (r *templateResolver) lookupDependency(ref string) *interfaces.Task {
+	if dep, ok := r.deps[ref]; ok {
+		return dep
+	}
+	for _, dep := range r.deps {
+		if dep.Name != "" && dep.Name == ref {
+			return dep
+		}
+	}
+	return nil
+}
+
+// splitPath splits a dotted path, treating bracketed indices as path segments
+func splitPath(path string) []string {
+	path = indexExpr.ReplaceAllString(strings.TrimSpace(path), ".$1")
+	parts := strings.Split(path, ".")
+
+	segments := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			segments = append(segments, part)
+		}
+	}
+
+	return segments
+}
+
+// lookupPath walks a value along the given path of object keys and list indices
+func lookupPath(value interface{}, path []string) (interface{}, error) {
+	current := value
+
+	for i, segment := range path {
+		node, err := normalizeValue(current)
+		if err != nil {
+			return nil, err
+		}
+
+		switch node := node.(type) {
+		case map[string]interface{}:
+			next, ok := node[segment]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found at %s", segment, describePath(path[:i]))
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("expected a list index at %s, got %q", describePath(path[:i]), segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("index %d out of range at %s (length %d)", index, describePath(path[:i]), len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("cannot look up %q at %s: value is %T", segment, describePath(path[:i]), current)
+		}
+	}
+
+	return normalizeValue(current)
+}
+
+// normalizeValue converts structs and typed collections into the generic
+// JSON representation so they can be walked by lookupPath
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string, bool, float64, map[string]interface{}, []interface{}:
+		return value, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return nil, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return generic, nil
+}
+
+// describePath renders a path prefix for error messages
+func describePath(path []string) string {
+	if len(path) == 0 {
+		return "the top level"
+	}
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/schema"
+)
+
+// executorParameters are task parameters consumed by the executor itself and
+// accepted for every task type
+var executorParameters = []string{"retry", "task_timeout"}
+
+// DescribeHandlers returns the description of every registered handler,
+// sorted by task type. Handlers that do not describe themselves are listed
+// with their task type only.
+func (e *TaskExecutorImpl) DescribeHandlers() []interfaces.HandlerDescription {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	descriptions := make([]interfaces.HandlerDescription, 0, len(e.handlers))
+	for taskType, handler := range e.handlers {
+		description := interfaces.HandlerDescription{TaskType: taskType}
+		if described, ok := handler.(interfaces.DescribedTaskHandler); ok {
+			description = described.Describe()
+			description.TaskType = taskType
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	sort.Slice(descriptions, func(i, j int) bool {
+		return descriptions[i].TaskType < descriptions[j].TaskType
+	})
+
+	return descriptions
+}
+
+// validateParameters checks the resolved parameters of a task against the
+// handler's schema. Handlers without a schema accept any parameters.
+func validateParameters(handler interfaces.TaskHandler, taskType, taskID string, params map[string]interface{}) error {
+	described, ok := handler.(interfaces.DescribedTaskHandler)
+	if !ok {
+		return nil
+	}
+
+	if err := checkParameters(described.Describe().Parameters, params, false); err != nil {
+		return fmt.Errorf("invalid parameters for %s task %s: %w", taskType, taskID, err)
+	}
+
+	return nil
+}
+
+// ValidatePlannedParameters checks the parameters of a task that has not run
+// yet against a handler description. Parameters holding template expressions
+// depend on the results of other tasks and are only checked when the task runs.
+func ValidatePlannedParameters(description interfaces.HandlerDescription, params map[string]interface{}) error {
+	return checkParameters(description.Parameters, params, true)
+}
+
+// checkParameters validates params against parameterSchema, ignoring the
+// executor's own parameters and optionally those that contain templates
+func checkParameters(parameterSchema map[string]interface{}, parameters map[string]interface{}, skipTemplates bool) error {
+	if parameterSchema == nil {
+		return nil
+	}
+
+	params := make(map[string]interface{}, len(parameters))
+	for key, value := range parameters {
+		params[key] = value
+	}
+	for _, key := range executorParameters {
+		delete(params, key)
+	}
+
+	err := schema.Validate(parameterSchema, params)
+	if err == nil || !skipTemplates {
+		return err
+	}
+
+	validationErr, ok := err.(*schema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	// Drop problems of templated parameters; their values are not known yet
+	var problems []string
+	for _, problem := range validationErr.Problems {
+		key := problem
+		if end := strings.IndexAny(key, ".[:"); end != -1 {
+			key = key[:end]
+		}
+		if containsTemplate(params[key]) {
+			continue
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &schema.ValidationError{Problems: problems}
+}
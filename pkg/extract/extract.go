@@ -0,0 +1,480 @@
+package extract
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Extraction kinds supported by Run
+const (
+	KindLinks    = "links"
+	KindTables   = "tables"
+	KindMeta     = "meta"
+	KindHeadings = "headings"
+	KindRecords  = "records"
+	KindText     = "text"
+)
+
+// Kinds lists every supported extraction kind
+var Kinds = []string{KindLinks, KindTables, KindMeta, KindHeadings, KindRecords, KindText}
+
+// Link is an anchor found in a document
+type Link struct {
+	URL  string `json:"url"`
+	Text string `json:"text"`
+	Rel  string `json:"rel,omitempty"`
+}
+
+// Table is an HTML table converted to rows. When the table has a header row,
+// each row is also available keyed by header in Records.
+type Table struct {
+	Caption string              `json:"caption,omitempty"`
+	Headers []string            `json:"headers,omitempty"`
+	Rows    [][]string          `json:"rows"`
+	Records []map[string]string `json:"records,omitempty"`
+}
+
+// Heading is an entry in the document outline
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id,omitempty"`
+}
+
+// Options configures an extraction
+type Options struct {
+	// BaseURL resolves relative links; defaults to the document's <base href>
+	BaseURL string
+	// ItemSelector selects one element per record (records only)
+	ItemSelector string
+	// Fields maps record field names to "selector" or "selector@attribute";
+	// an empty selector refers to the item element itself, and an "@" inside
+	// an attribute selector is part of the selector (records only)
+	Fields map[string]string
+}
+
+// Document is a parsed HTML document
+type Document struct {
+	root *html.Node
+}
+
+// Parse parses an HTML document
+func Parse(content string) (*Document, error) {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	return &Document{root: root}, nil
+}
+
+// Run parses content and performs the extraction of the given kind
+func Run(kind, content string, opts Options) (interface{}, error) {
+	doc, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case KindLinks:
+		return doc.Links(opts.BaseURL)
+	case KindTables:
+		return doc.Tables(), nil
+	case KindMeta:
+		return doc.Meta(), nil
+	case KindHeadings:
+		return doc.Headings(), nil
+	case KindRecords:
+		return doc.Records(opts.ItemSelector, opts.Fields)
+	case KindText:
+		return doc.Text(), nil
+	default:
+		return nil, fmt.Errorf("unsupported extraction kind: %s", kind)
+	}
+}
+
+// Links returns every anchor with an href, resolved to an absolute URL and
+// de-duplicated by URL
+func (d *Document) Links(baseURL string) ([]Link, error) {
+	base, err := d.baseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	links := []Link{}
+
+	for _, n := range d.elements("a") {
+		href, ok := attrValue(n, "href")
+		href = strings.TrimSpace(href)
+		if !ok || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			continue
+		}
+
+		ref, err := url.Parse(href)
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			ref = base.ResolveReference(ref)
+		}
+		ref.Fragment = ""
+
+		absolute := ref.String()
+		if seen[absolute] {
+			continue
+		}
+		seen[absolute] = true
+
+		text := nodeText(n)
+		if text == "" {
+			text = attr(n, "title")
+		}
+
+		links = append(links, Link{
+			URL:  absolute,
+			Text: text,
+			Rel:  attr(n, "rel"),
+		})
+	}
+
+	return links, nil
+}
+
+// Tables returns every table in the document
+func (d *Document) Tables() []Table {
+	tables := []Table{}
+
+	for _, n := range d.elements("table") {
+		table := Table{Rows: [][]string{}}
+
+		if caption := findFirst(n, "caption"); caption != nil {
+			table.Caption = nodeText(caption)
+		}
+
+		for _, row := range tableRows(n) {
+			var cells []string
+			allHeaders := true
+			for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") {
+					continue
+				}
+				if cell.Data == "td" {
+					allHeaders = false
+				}
+				cells = append(cells, nodeText(cell))
+			}
+			if len(cells) == 0 {
+				continue
+			}
+
+			if allHeaders && table.Headers == nil && len(table.Rows) == 0 {
+				table.Headers = cells
+				continue
+			}
+			table.Rows = append(table.Rows, cells)
+		}
+
+		if len(table.Headers) > 0 {
+			for _, row := range table.Rows {
+				record := make(map[string]string, len(table.Headers))
+				for i, header := range table.Headers {
+					if i < len(row) {
+						record[header] = row[i]
+					}
+				}
+				table.Records = append(table.Records, record)
+			}
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables
+}
+
+// Meta returns the document title, canonical URL and meta tags keyed by
+// their name, property or http-equiv attribute
+func (d *Document) Meta() map[string]string {
+	meta := make(map[string]string)
+
+	if title := findFirst(d.root, "title"); title != nil {
+		meta["title"] = nodeText(title)
+	}
+
+	for _, n := range d.elements("meta") {
+		content, ok := attrValue(n, "content")
+		if !ok {
+			if charset := attr(n, "charset"); charset != "" {
+				meta["charset"] = charset
+			}
+			continue
+		}
+
+		for _, key := range []string{"name", "property", "http-equiv", "itemprop"} {
+			if name := attr(n, key); name != "" {
+				meta[strings.ToLower(name)] = content
+				break
+			}
+		}
+	}
+
+	for _, n := range d.elements("link") {
+		if strings.EqualFold(attr(n, "rel"), "canonical") {
+			meta["canonical"] = attr(n, "href")
+		}
+	}
+
+	return meta
+}
+
+// Headings returns the h1-h6 outline of the document in order
+func (d *Document) Headings() []Heading {
+	headings := []Heading{}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+			if text := nodeText(n); text != "" {
+				headings = append(headings, Heading{
+					Level: int(n.Data[1] - '0'),
+					Text:  text,
+					ID:    attr(n, "id"),
+				})
+			}
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(d.root)
+
+	return headings
+}
+
+// Records selects one element per record with itemSelector and extracts each
+// field from it
+func (d *Document) Records(itemSelector string, fields map[string]string) ([]map[string]string, error) {
+	if itemSelector == "" {
+		return nil, fmt.Errorf("records extraction requires an item selector")
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("records extraction requires at least one field")
+	}
+
+	items, err := CompileSelector(itemSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	type fieldSpec struct {
+		selector  *Selector
+		attribute string
+	}
+	specs := make(map[string]fieldSpec, len(fields))
+	for name, spec := range fields {
+		selector, attribute := splitFieldSpec(spec)
+
+		var compiled *Selector
+		if strings.TrimSpace(selector) != "" {
+			compiled, err = CompileSelector(selector)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", name, err)
+			}
+		}
+		specs[name] = fieldSpec{selector: compiled, attribute: attribute}
+	}
+
+	records := []map[string]string{}
+	for _, item := range items.MatchAll(d.root) {
+		record := make(map[string]string, len(specs))
+		for name, spec := range specs {
+			target := item
+			if spec.selector != nil {
+				target = spec.selector.MatchFirst(item)
+			}
+			if target == nil {
+				continue
+			}
+			if spec.attribute != "" {
+				record[name] = attr(target, spec.attribute)
+			} else {
+				record[name] = nodeText(target)
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// splitFieldSpec splits a record field spec into its selector and the
+// attribute named by a trailing "@name". An "@" inside brackets or quotes,
+// as in a[href^="mailto:x@y"], belongs to the selector.
+func splitFieldSpec(spec string) (selector, attribute string) {
+	at := -1
+	depth := 0
+	var quote byte
+	for i := 0; i < len(spec); i++ {
+		c := spec[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '@' && depth == 0:
+			at = i
+		}
+	}
+
+	if at == -1 || !isAttributeName(spec[at+1:]) {
+		return spec, ""
+	}
+	return spec[:at], spec[at+1:]
+}
+
+// isAttributeName reports whether name is a valid HTML attribute name
+func isAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || strings.ContainsRune(`"'>/=[]@`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Text returns the visible text of the document
+func (d *Document) Text() string {
+	body := findFirst(d.root, "body")
+	if body == nil {
+		body = d.root
+	}
+	return nodeText(body)
+}
+
+// baseURL determines the URL relative links are resolved against
+func (d *Document) baseURL(override string) (*url.URL, error) {
+	candidate := override
+	if candidate == "" {
+		if base := findFirst(d.root, "base"); base != nil {
+			candidate = attr(base, "href")
+		}
+	}
+	if candidate == "" {
+		return nil, nil
+	}
+
+	base, err := url.Parse(candidate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL %q: %w", candidate, err)
+	}
+	return base, nil
+}
+
+// elements returns every element with the given tag in document order
+func (d *Document) elements(tag string) []*html.Node {
+	var nodes []*html.Node
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == tag {
+			nodes = append(nodes, n)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(d.root)
+
+	return nodes
+}
+
+// tableRows returns the rows of a table without descending into nested tables
+func tableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.Data {
+			case "tr":
+				rows = append(rows, child)
+			case "thead", "tbody", "tfoot":
+				walk(child)
+			}
+		}
+	}
+	walk(table)
+
+	return rows
+}
+
+// findFirst returns the first element with the given tag below n
+func findFirst(n *html.Node, tag string) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == tag {
+			return child
+		}
+		if found := findFirst(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText returns the whitespace-normalized text below n, skipping scripts and styles
+func nodeText(n *html.Node) string {
+	var builder strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			builder.WriteString(n.Data)
+			builder.WriteByte(' ')
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// attr returns the value of an attribute or an empty string
+func attr(n *html.Node, name string) string {
+	value, _ := attrValue(n, name)
+	return value
+}
+
+// attrValue returns the value of an attribute and whether it is present
+func attrValue(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, name) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,138 @@
+package extract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Go Packages</title>
+  <base href="https://pkg.go.dev/">
+  <meta name="description" content="Search for Go packages">
+  <meta property="og:title" content="Go Packages">
+  <link rel="canonical" href="https://pkg.go.dev/search">
+  <script>var ignored = "<a href='/nope'>no</a>";</script>
+</head>
+<body>
+  <h1 id="top">Results</h1>
+  <a href="#top">Back to top</a>
+  <div class="results">
+    <div class="result">
+      <h2><a href="/net/http">net/http</a></h2>
+      <span class="synopsis">HTTP client and server</span>
+    </div>
+    <div class="result featured">
+      <h2><a href="/encoding/json">encoding/json</a></h2>
+      <span class="synopsis">JSON encoding</span>
+    </div>
+  </div>
+  <a href="/net/http#pkg-overview">net/http overview</a>
+  <a href="https://go.dev/">Go</a>
+  <table>
+    <caption>Versions</caption>
+    <tr><th>Version</th><th>Date</th></tr>
+    <tr><td>v1.22</td><td>2024-02-06</td></tr>
+    <tr><td>v1.21</td><td>2023-08-08</td></tr>
+  </table>
+</body>
+</html>`
+
+func TestLinksResolvesAndDeduplicates(t *testing.T) {
+	doc, err := Parse(samplePage)
+	require.NoError(t, err)
+
+	links, err := doc.Links("")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Link{
+		{URL: "https://pkg.go.dev/net/http", Text: "net/http"},
+		{URL: "https://pkg.go.dev/encoding/json", Text: "encoding/json"},
+		{URL: "https://go.dev/", Text: "Go"},
+	}, links)
+}
+
+func TestTablesUseHeaderRow(t *testing.T) {
+	doc, err := Parse(samplePage)
+	require.NoError(t, err)
+
+	tables := doc.Tables()
+	require.Len(t, tables, 1)
+	assert.Equal(t, "Versions", tables[0].Caption)
+	assert.Equal(t, []string{"Version", "Date"}, tables[0].Headers)
+	assert.Equal(t, [][]string{{"v1.22", "2024-02-06"}, {"v1.21", "2023-08-08"}}, tables[0].Rows)
+	assert.Equal(t, "2023-08-08", tables[0].Records[1]["Date"])
+}
+
+func TestMetaAndHeadings(t *testing.T) {
+	doc, err := Parse(samplePage)
+	require.NoError(t, err)
+
+	meta := doc.Meta()
+	assert.Equal(t, "Go Packages", meta["title"])
+	assert.Equal(t, "Search for Go packages", meta["description"])
+	assert.Equal(t, "Go Packages", meta["og:title"])
+	assert.Equal(t, "https://pkg.go.dev/search", meta["canonical"])
+
+	assert.Equal(t, []Heading{
+		{Level: 1, Text: "Results", ID: "top"},
+		{Level: 2, Text: "net/http"},
+		{Level: 2, Text: "encoding/json"},
+	}, doc.Headings())
+}
+
+func TestRecordsWithSelectors(t *testing.T) {
+	records, err := Run(KindRecords, samplePage, Options{
+		ItemSelector: "div.results > .result",
+		Fields: map[string]string{
+			"name":     "h2 a",
+			"href":     "a[href^='/']@href",
+			"synopsis": ".synopsis",
+			"class":    "@class",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]string{
+		{"name": "net/http", "href": "/net/http", "synopsis": "HTTP client and server", "class": "result"},
+		{"name": "encoding/json", "href": "/encoding/json", "synopsis": "JSON encoding", "class": "result featured"},
+	}, records)
+}
+
+func TestSplitFieldSpec(t *testing.T) {
+	tests := []struct {
+		spec      string
+		selector  string
+		attribute string
+	}{
+		{"h2 a", "h2 a", ""},
+		{"a@href", "a", "href"},
+		{"@class", "", "class"},
+		{`a[href^="mailto:x@y"]`, `a[href^="mailto:x@y"]`, ""},
+		{`a[href^='mailto:x@y']@href`, `a[href^='mailto:x@y']`, "href"},
+		{"a[data-x=x@y]", "a[data-x=x@y]", ""},
+	}
+
+	for _, tt := range tests {
+		selector, attribute := splitFieldSpec(tt.spec)
+		assert.Equal(t, tt.selector, selector, tt.spec)
+		assert.Equal(t, tt.attribute, attribute, tt.spec)
+	}
+}
+
+func TestCompileSelectorErrors(t *testing.T) {
+	for _, selector := range []string{"", "> a", "a >", "div[", "a,", "#"} {
+		_, err := CompileSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+func TestTextSkipsScripts(t *testing.T) {
+	text, err := Run(KindText, samplePage, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, text, "HTTP client and server")
+	assert.NotContains(t, text, "ignored")
+}
@@ -0,0 +1,327 @@
+package extract
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector is a compiled CSS selector supporting type, id, class and attribute
+// selectors combined with descendant (" ") and child (">") combinators, plus
+// comma-separated selector lists
+type Selector struct {
+	alternatives [][]compoundStep
+}
+
+// compoundStep is one compound selector and the combinator linking it to the previous step
+type compoundStep struct {
+	combinator byte // ' ' for descendant, '>' for child, 0 for the first step
+	tag        string
+	id         string
+	classes    []string
+	attrs      []attrMatcher
+}
+
+// attrMatcher matches a single [attr], [attr=v], [attr*=v], [attr^=v] or [attr$=v] expression
+type attrMatcher struct {
+	name     string
+	operator string
+	value    string
+}
+
+// CompileSelector parses a CSS selector
+func CompileSelector(selector string) (*Selector, error) {
+	compiled := &Selector{}
+
+	for _, part := range splitTopLevel(selector, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty selector in %q", selector)
+		}
+
+		steps, err := parseComplex(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", part, err)
+		}
+		compiled.alternatives = append(compiled.alternatives, steps)
+	}
+
+	if len(compiled.alternatives) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	return compiled, nil
+}
+
+// MatchAll returns every element below root matching the selector, in document order
+func (s *Selector) MatchAll(root *html.Node) []*html.Node {
+	var matches []*html.Node
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && s.Match(child) {
+				matches = append(matches, child)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return matches
+}
+
+// MatchFirst returns the first element below root matching the selector
+func (s *Selector) MatchFirst(root *html.Node) *html.Node {
+	matches := s.MatchAll(root)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// Match reports whether the element matches the selector
+func (s *Selector) Match(n *html.Node) bool {
+	for _, steps := range s.alternatives {
+		if matchSteps(n, steps, len(steps)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSteps matches steps[0..last] right to left, starting with n for steps[last]
+func matchSteps(n *html.Node, steps []compoundStep, last int) bool {
+	if !steps[last].matches(n) {
+		return false
+	}
+	if last == 0 {
+		return true
+	}
+
+	switch steps[last].combinator {
+	case '>':
+		parent := n.Parent
+		return parent != nil && parent.Type == html.ElementNode && matchSteps(parent, steps, last-1)
+	default:
+		for ancestor := n.Parent; ancestor != nil; ancestor = ancestor.Parent {
+			if ancestor.Type == html.ElementNode && matchSteps(ancestor, steps, last-1) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matches reports whether an element satisfies a compound selector
+func (c compoundStep) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && n.Data != c.tag {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(attr(n, "class"))
+		for _, want := range c.classes {
+			found := false
+			for _, class := range classes {
+				if class == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	for _, matcher := range c.attrs {
+		value, ok := attrValue(n, matcher.name)
+		if !ok {
+			return false
+		}
+		switch matcher.operator {
+		case "":
+		case "=":
+			if value != matcher.value {
+				return false
+			}
+		case "*=":
+			if !strings.Contains(value, matcher.value) {
+				return false
+			}
+		case "^=":
+			if !strings.HasPrefix(value, matcher.value) {
+				return false
+			}
+		case "$=":
+			if !strings.HasSuffix(value, matcher.value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseComplex parses a complex selector such as "div.results > a[href]"
+func parseComplex(selector string) ([]compoundStep, error) {
+	var steps []compoundStep
+	combinator := byte(0)
+	i := 0
+
+	for i < len(selector) {
+		switch selector[i] {
+		case ' ', '\t', '\n':
+			if combinator == 0 && len(steps) > 0 {
+				combinator = ' '
+			}
+			i++
+			continue
+		case '>':
+			if len(steps) == 0 {
+				return nil, fmt.Errorf("selector cannot start with '>'")
+			}
+			combinator = '>'
+			i++
+			continue
+		}
+
+		step, next, err := parseCompound(selector, i)
+		if err != nil {
+			return nil, err
+		}
+		if len(steps) > 0 && combinator == 0 {
+			combinator = ' '
+		}
+		step.combinator = combinator
+		steps = append(steps, step)
+		combinator = 0
+		i = next
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	if combinator == '>' {
+		return nil, fmt.Errorf("selector cannot end with '>'")
+	}
+
+	return steps, nil
+}
+
+// parseCompound parses a compound selector starting at position i
+func parseCompound(selector string, i int) (compoundStep, int, error) {
+	var step compoundStep
+	start := i
+
+	readIdent := func() string {
+		begin := i
+		for i < len(selector) && isIdentChar(selector[i]) {
+			i++
+		}
+		return selector[begin:i]
+	}
+
+	if i < len(selector) && selector[i] == '*' {
+		step.tag = "*"
+		i++
+	} else if i < len(selector) && isIdentChar(selector[i]) {
+		step.tag = strings.ToLower(readIdent())
+	}
+
+	for i < len(selector) {
+		switch selector[i] {
+		case '#':
+			i++
+			step.id = readIdent()
+			if step.id == "" {
+				return step, i, fmt.Errorf("missing id after '#'")
+			}
+		case '.':
+			i++
+			class := readIdent()
+			if class == "" {
+				return step, i, fmt.Errorf("missing class after '.'")
+			}
+			step.classes = append(step.classes, class)
+		case '[':
+			end := strings.IndexByte(selector[i:], ']')
+			if end == -1 {
+				return step, i, fmt.Errorf("unterminated attribute selector")
+			}
+			matcher, err := parseAttr(selector[i+1 : i+end])
+			if err != nil {
+				return step, i, err
+			}
+			step.attrs = append(step.attrs, matcher)
+			i += end + 1
+		default:
+			if i == start {
+				return step, i, fmt.Errorf("unexpected character %q", selector[i])
+			}
+			return step, i, nil
+		}
+	}
+
+	return step, i, nil
+}
+
+// parseAttr parses the inside of an attribute selector
+func parseAttr(expr string) (attrMatcher, error) {
+	for _, operator := range []string{"*=", "^=", "$=", "="} {
+		if idx := strings.Index(expr, operator); idx != -1 {
+			name := strings.TrimSpace(expr[:idx])
+			value := strings.TrimSpace(expr[idx+len(operator):])
+			value = strings.Trim(value, `"'`)
+			if name == "" {
+				return attrMatcher{}, fmt.Errorf("missing attribute name in [%s]", expr)
+			}
+			return attrMatcher{name: strings.ToLower(name), operator: operator, value: value}, nil
+		}
+	}
+
+	name := strings.TrimSpace(expr)
+	if name == "" {
+		return attrMatcher{}, fmt.Errorf("empty attribute selector")
+	}
+	return attrMatcher{name: strings.ToLower(name)}, nil
+}
+
+// splitTopLevel splits s on sep, ignoring separators inside brackets or quotes
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	quote := byte(0)
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// isIdentChar reports whether c may appear in a CSS identifier
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
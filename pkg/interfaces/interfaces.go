@@ -8,6 +8,7 @@ import (
 // Task represents a unit of work in the agent framework
 type Task struct {
 	ID           string                 `json:"id"`
+	Name         string                 `json:"name,omitempty"`
 	Type         string                 `json:"type"`
 	Description  string                 `json:"description"`
 	Parameters   map[string]interface{} `json:"parameters"`
@@ -17,6 +18,45 @@ type Task struct {
 	UpdatedAt    time.Time              `json:"updated_at"`
 	Result       interface{}            `json:"result,omitempty"`
 	Error        string                 `json:"error,omitempty"`
+	Attempts     []TaskAttempt          `json:"attempts,omitempty"`
+}
+
+// TaskAttempt records a single execution attempt of a task
+type TaskAttempt struct {
+	Number     int       `json:"number"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+	ErrorClass string    `json:"error_class,omitempty"`
+}
+
+// RetryPolicy describes how a failed task is retried
+type RetryPolicy struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	Multiplier     float64       `json:"multiplier"`
+	Jitter         float64       `json:"jitter"`
+	RetryOn        []string      `json:"retry_on,omitempty"`
+}
+
+// HandlerConfig holds execution defaults applied to every task of a handler's type
+type HandlerConfig struct {
+	RetryPolicy *RetryPolicy
+	// Timeout bounds each attempt of a task, not the task as a whole
+	Timeout time.Duration
+}
+
+// HandlerOption configures a handler at registration time
+type HandlerOption func(*HandlerConfig)
+
+// HandlerDescription describes the actions a task handler supports and the
+// JSON schema of the parameters it accepts
+type HandlerDescription struct {
+	TaskType    string                 `json:"task_type"`
+	Description string                 `json:"description"`
+	Actions     []string               `json:"actions,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
 }
 
 // TaskStatus represents the current state of a task
@@ -28,6 +68,8 @@ const (
 	TaskStatusCompleted TaskStatus = "completed"
 	TaskStatusFailed    TaskStatus = "failed"
 	TaskStatusCancelled TaskStatus = "cancelled"
+	TaskStatusSkipped   TaskStatus = "skipped"
+	TaskStatusTimedOut  TaskStatus = "timed_out"
 )
 
 // Plan represents a collection of tasks with dependencies
@@ -36,24 +78,184 @@ type Plan struct {
 	Goal      string     `json:"goal"`
 	Tasks     []Task     `json:"tasks"`
 	Status    TaskStatus `json:"status"`
+	Replans   int        `json:"replans,omitempty"`
+	Revision  int        `json:"revision,omitempty"`
+	Answer    string     `json:"answer,omitempty"`
+	Usage     *PlanUsage `json:"usage,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 }
 
+// LLMUsage sums the tokens and time spent on LLM calls
+type LLMUsage struct {
+	Calls            int           `json:"calls"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TotalTokens      int           `json:"total_tokens"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// PlanUsage is the LLM usage of a plan, in total and broken down by stage and
+// by task ID. Calls made outside of tasks, such as planning, have no task.
+type PlanUsage struct {
+	LLMUsage
+	TokenBudget int                 `json:"token_budget,omitempty"`
+	ByStage     map[string]LLMUsage `json:"by_stage,omitempty"`
+	ByTask      map[string]LLMUsage `json:"by_task,omitempty"`
+}
+
+// PlanRevision is an immutable snapshot of the tasks of a plan version
+type PlanRevision struct {
+	Number    int       `json:"number"`
+	Tasks     []Task    `json:"tasks"`
+	Feedback  string    `json:"feedback,omitempty"`
+	Diff      *PlanDiff `json:"diff,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PlanDiff lists the structural changes between two plan revisions. Tasks are
+// matched and identified by name.
+type PlanDiff struct {
+	Added     []string     `json:"added,omitempty"`
+	Removed   []string     `json:"removed,omitempty"`
+	Modified  []TaskChange `json:"modified,omitempty"`
+	Unchanged []string     `json:"unchanged,omitempty"`
+}
+
+// TaskChange names the fields of a task that differ between two revisions
+type TaskChange struct {
+	Name   string   `json:"name"`
+	Fields []string `json:"fields"`
+}
+
 // LLMRequest represents a request to the local LLM
 type LLMRequest struct {
-	Model   string                 `json:"model"`
-	Prompt  string                 `json:"prompt"`
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	// Format constrains the output: "json" or a JSON schema object
+	Format  interface{}            `json:"format,omitempty"`
 	Stream  bool                   `json:"stream"`
 	Options map[string]interface{} `json:"options,omitempty"`
+	// Stage names the framework stage issuing the request, such as
+	// "planning", and selects its model route; it is not sent to the LLM
+	Stage string `json:"-"`
 }
 
-// LLMResponse represents a response from the local LLM
+// LLMResponse represents a response from the local LLM. Response holds the
+// final answer only; the reasoning of thinking models such as deepseek-r1 is
+// returned separately.
 type LLMResponse struct {
-	Model    string `json:"model"`
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-	Context  []int  `json:"context,omitempty"`
+	Model     string `json:"model"`
+	Response  string `json:"response"`
+	Reasoning string `json:"thinking,omitempty"`
+	Done      bool   `json:"done"`
+	Context   []int  `json:"context,omitempty"`
+	LLMStats
+}
+
+// Chat message roles
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+	ChatRoleTool      = "tool"
+)
+
+// ChatMessage is a role-tagged message of a conversation. Assistant messages
+// may request tool calls; tool messages return a call's result and name the
+// call by ToolCallID when the backend assigned one.
+type ChatMessage struct {
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	Reasoning  string     `json:"thinking,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolName   string     `json:"tool_name,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction names a callable function and the JSON schema of its arguments
+type ToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction holds the name and arguments of a requested call
+type ToolCallFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ChatRequest represents a multi-turn request to the local LLM. System
+// prompts are passed as messages with the system role.
+type ChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Tools    []Tool        `json:"tools,omitempty"`
+	// Format constrains the output: "json" or a JSON schema object
+	Format  interface{}            `json:"format,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Stage selects the model route like LLMRequest.Stage
+	Stage string `json:"-"`
+}
+
+// ChatResponse represents the reply to a chat request
+type ChatResponse struct {
+	Model   string      `json:"model"`
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	LLMStats
+}
+
+// EmbeddingRequest asks for vector embeddings of a batch of inputs
+type EmbeddingRequest struct {
+	Model   string                 `json:"model"`
+	Input   []string               `json:"input"`
+	Options map[string]interface{} `json:"options,omitempty"`
+	// Stage selects the model route like LLMRequest.Stage
+	Stage string `json:"-"`
+}
+
+// EmbeddingResponse holds one embedding per input, in input order, each of
+// Dimensions values
+type EmbeddingResponse struct {
+	Model      string      `json:"model"`
+	Embeddings [][]float32 `json:"embeddings"`
+	Dimensions int         `json:"dimensions"`
+	LLMStats
+}
+
+// LLMStats holds the generation statistics reported with a completed response
+type LLMStats struct {
+	TotalDuration   time.Duration `json:"total_duration,omitempty"`
+	PromptEvalCount int           `json:"prompt_eval_count,omitempty"`
+	EvalCount       int           `json:"eval_count,omitempty"`
+	EvalDuration    time.Duration `json:"eval_duration,omitempty"`
+}
+
+// LLMChunk is a piece of a streamed LLM response. The final chunk has Done
+// set and carries the statistics; a chunk with Err set ends a failed stream.
+// Reasoning of thinking models arrives apart from the answer text.
+type LLMChunk struct {
+	Model     string `json:"model"`
+	Response  string `json:"response"`
+	Reasoning string `json:"thinking,omitempty"`
+	Done      bool   `json:"done"`
+	Context   []int  `json:"context,omitempty"`
+	LLMStats
+	Err error `json:"-"`
 }
 
 // BrowserAction represents an action to be performed in the browser
@@ -79,14 +281,19 @@ type Planner interface {
 	CreatePlan(ctx context.Context, goal string) (*Plan, error)
 	UpdatePlan(ctx context.Context, planID string, feedback string) (*Plan, error)
 	GetPlan(ctx context.Context, planID string) (*Plan, error)
+	// GetPlanHistory returns every revision of a plan, oldest first
+	GetPlanHistory(ctx context.Context, planID string) ([]PlanRevision, error)
+	GetPlanRevision(ctx context.Context, planID string, revision int) (*PlanRevision, error)
 }
 
 // TaskExecutor interface defines task execution capabilities
 type TaskExecutor interface {
 	ExecuteTask(ctx context.Context, task *Task) error
+	WaitTask(ctx context.Context, taskID string) (*Task, error)
 	GetTaskStatus(ctx context.Context, taskID string) (TaskStatus, error)
 	CancelTask(ctx context.Context, taskID string) error
-	RegisterHandler(taskType string, handler TaskHandler)
+	RegisterHandler(taskType string, handler TaskHandler, opts ...HandlerOption)
+	HandlerCatalog
 }
 
 // TaskHandler interface for specific task type handlers
@@ -95,6 +302,18 @@ type TaskHandler interface {
 	CanHandle(taskType string) bool
 }
 
+// DescribedTaskHandler is implemented by task handlers that publish their
+// supported actions and parameter schema
+type DescribedTaskHandler interface {
+	TaskHandler
+	Describe() HandlerDescription
+}
+
+// HandlerCatalog lists the registered task handlers
+type HandlerCatalog interface {
+	DescribeHandlers() []HandlerDescription
+}
+
 // BrowserAgent interface defines browser automation capabilities
 type BrowserAgent interface {
 	Navigate(ctx context.Context, url string) error
@@ -125,9 +344,22 @@ type LangGraphEngine interface {
 // LLMClient interface defines local LLM interaction capabilities
 type LLMClient interface {
 	Generate(ctx context.Context, request LLMRequest) (*LLMResponse, error)
+	// Chat continues a conversation of role-tagged messages, optionally
+	// offering tools the model may call
+	Chat(ctx context.Context, request ChatRequest) (*ChatResponse, error)
+	// Embed returns vector embeddings of the request's inputs
+	Embed(ctx context.Context, request EmbeddingRequest) (*EmbeddingResponse, error)
 	IsHealthy(ctx context.Context) bool
 }
 
+// StreamingLLMClient is implemented by clients that can stream responses. The
+// returned channel is closed after the final chunk or a chunk carrying an
+// error, and when ctx is cancelled.
+type StreamingLLMClient interface {
+	LLMClient
+	GenerateStream(ctx context.Context, request LLMRequest) (<-chan LLMChunk, error)
+}
+
 // Logger interface defines logging capabilities
 type Logger interface {
 	Debug(args ...interface{})
@@ -150,5 +382,12 @@ type AgentFramework interface {
 	Start(ctx context.Context) error
 	Stop(ctx context.Context) error
 	ExecuteGoal(ctx context.Context, goal string) (*Plan, error)
+	// ExecuteGoalIterative pursues a goal one LLM-chosen task at a time,
+	// observing each result before deciding the next step
+	ExecuteGoalIterative(ctx context.Context, goal string) (*Plan, error)
+	// ExecutePlan runs a prepared plan, such as one loaded from a plan
+	// file, without asking the planner
+	ExecutePlan(ctx context.Context, plan *Plan) (*Plan, error)
+	HandlerCatalog
 	GetStatus(ctx context.Context) (map[string]interface{}, error)
 }
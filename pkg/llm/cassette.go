@@ -0,0 +1,363 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// Cassette modes selecting how LLM calls are recorded or replayed
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// Kinds of recorded calls
+const (
+	callGenerate = "generate"
+	callChat     = "chat"
+	callEmbed    = "embed"
+)
+
+// Interaction is a recorded request and the response it received
+type Interaction struct {
+	Key      string          `json:"key"`
+	Kind     string          `json:"kind"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// cassette is the file format of recorded interactions
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// RequestKey hashes a normalized request so equal requests share a key
+// regardless of streaming, stage and map ordering
+func RequestKey(kind string, request interface{}) (string, error) {
+	normalized, err := normalizeRequest(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(kind+"\n"), normalized...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeRequest encodes a request canonically. Streaming only changes how
+// a response is delivered and the stage is not sent, so neither is part of it.
+func normalizeRequest(request interface{}) ([]byte, error) {
+	if generate, ok := request.(interfaces.LLMRequest); ok {
+		generate.Stream = false
+		request = generate
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	// Decoding into generic values and encoding again sorts object keys,
+	// including those of raw JSON formats
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to normalize request: %w", err)
+	}
+	return json.Marshal(value)
+}
+
+// RecordingClient forwards requests to a client and writes every successful
+// request/response pair to a cassette file, which ReplayClient serves back
+type RecordingClient struct {
+	client interfaces.LLMClient
+	path   string
+	logger interfaces.Logger
+
+	mu       sync.Mutex
+	recorded cassette
+}
+
+// NewRecordingClient wraps client, recording to the cassette at path. An
+// existing cassette is replaced.
+func NewRecordingClient(client interfaces.LLMClient, path string, logger interfaces.Logger) (*RecordingClient, error) {
+	c := &RecordingClient{
+		client: client,
+		path:   path,
+		logger: logger,
+	}
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Generate forwards the request and records its response
+func (c *RecordingClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	resp, err := c.client.Generate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record(callGenerate, request, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GenerateStream streams the response of the wrapped client and records it
+// once the final chunk has arrived
+func (c *RecordingClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	upstream, err := Stream(ctx, c.client, request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+
+		var text, reasoning strings.Builder
+		for chunk := range upstream {
+			text.WriteString(chunk.Response)
+			reasoning.WriteString(chunk.Reasoning)
+			if chunk.Done {
+				resp := &interfaces.LLMResponse{
+					Model:     chunk.Model,
+					Response:  text.String(),
+					Reasoning: reasoning.String(),
+					Done:      true,
+					Context:   chunk.Context,
+					LLMStats:  chunk.LLMStats,
+				}
+				if err := c.record(callGenerate, request, resp); err != nil {
+					chunk = interfaces.LLMChunk{Err: err}
+				}
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			if chunk.Done || chunk.Err != nil {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// Chat forwards the request and records its response
+func (c *RecordingClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	resp, err := c.client.Chat(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record(callChat, request, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Embed forwards the request and records its response
+func (c *RecordingClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	resp, err := c.client.Embed(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record(callEmbed, request, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// IsHealthy reports whether the wrapped client is healthy
+func (c *RecordingClient) IsHealthy(ctx context.Context) bool {
+	return c.client.IsHealthy(ctx)
+}
+
+// record appends an interaction and rewrites the cassette, so recordings of
+// interrupted runs are kept
+func (c *RecordingClient) record(kind string, request, response interface{}) error {
+	key, err := RequestKey(kind, request)
+	if err != nil {
+		return err
+	}
+	requestData, err := normalizeRequest(request)
+	if err != nil {
+		return err
+	}
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recorded.Interactions = append(c.recorded.Interactions, Interaction{
+		Key:      key,
+		Kind:     kind,
+		Request:  requestData,
+		Response: responseData,
+	})
+
+	c.logger.WithFields(map[string]interface{}{
+		"kind":     kind,
+		"key":      key,
+		"cassette": c.path,
+	}).Debug("Recorded LLM interaction")
+
+	return c.save()
+}
+
+// save writes the cassette through a temporary file so it is never left
+// half-written
+func (c *RecordingClient) save() error {
+	data, err := json.MarshalIndent(c.recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// ReplayClient serves responses recorded by RecordingClient without a model
+// server. Requests that were recorded several times get their responses in
+// recording order, the last one repeating; requests that were never
+// recorded fail.
+type ReplayClient struct {
+	interactions map[string][]Interaction
+	path         string
+	logger       interfaces.Logger
+
+	mu     sync.Mutex
+	served map[string]int
+}
+
+// NewReplayClient loads the cassette at path
+func NewReplayClient(path string, logger interfaces.Logger) (*ReplayClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var loaded cassette
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	c := &ReplayClient{
+		interactions: make(map[string][]Interaction),
+		path:         path,
+		logger:       logger,
+		served:       make(map[string]int),
+	}
+	for _, interaction := range loaded.Interactions {
+		c.interactions[interaction.Key] = append(c.interactions[interaction.Key], interaction)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"cassette":     path,
+		"interactions": len(loaded.Interactions),
+	}).Info("Loaded LLM cassette")
+
+	return c, nil
+}
+
+// Generate returns the response recorded for the request
+func (c *ReplayClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	var resp interfaces.LLMResponse
+	if err := c.replay(callGenerate, request, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Chat returns the response recorded for the request
+func (c *ReplayClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	var resp interfaces.ChatResponse
+	if err := c.replay(callChat, request, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Embed returns the response recorded for the request
+func (c *ReplayClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	var resp interfaces.EmbeddingResponse
+	if err := c.replay(callEmbed, request, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// IsHealthy always succeeds since no model server is involved
+func (c *ReplayClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+// replay decodes the next response recorded for the request into response
+func (c *ReplayClient) replay(kind string, request, response interface{}) error {
+	key, err := RequestKey(kind, request)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	recorded := c.interactions[key]
+	index := c.served[key]
+	if index < len(recorded) {
+		c.served[key]++
+	} else {
+		index = len(recorded) - 1
+	}
+	c.mu.Unlock()
+
+	if len(recorded) == 0 {
+		requestData, _ := normalizeRequest(request)
+		c.logger.WithFields(map[string]interface{}{
+			"kind":     kind,
+			"key":      key,
+			"cassette": c.path,
+		}).Error("LLM request was not recorded")
+		return fmt.Errorf("no recorded %s response in cassette %s for request %s: %s",
+			kind, c.path, key[:12], truncateRequest(requestData))
+	}
+
+	if err := json.Unmarshal(recorded[index].Response, response); err != nil {
+		return fmt.Errorf("failed to decode recorded response: %w", err)
+	}
+	return nil
+}
+
+// truncateRequest shortens an encoded request for error messages
+func truncateRequest(data []byte) string {
+	const limit = 300
+	if len(data) <= limit {
+		return string(data)
+	}
+	return string(data[:limit]) + "..."
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceClient answers every generate request with the next response
+type sequenceClient struct {
+	recordingClient
+	responses []string
+}
+
+func (c *sequenceClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.requests = append(c.requests, request)
+	response := c.responses[(len(c.requests)-1)%len(c.responses)]
+	return &interfaces.LLMResponse{Model: "llama3", Response: response, Done: true}, nil
+}
+
+func TestRecordingClientRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogrusLogger("error")
+	path := filepath.Join(t.TempDir(), "llm.json")
+
+	base := &sequenceClient{responses: []string{"first", "second"}}
+	recorder, err := NewRecordingClient(base, path, log)
+	require.NoError(t, err)
+
+	request := interfaces.LLMRequest{
+		Prompt:  "plan this",
+		Format:  map[string]interface{}{"type": "object", "required": []string{"tasks"}},
+		Options: map[string]interface{}{"temperature": 0.7, "num_predict": 2000},
+		Stage:   StagePlanning,
+	}
+	for _, expected := range []string{"first", "second"} {
+		resp, err := recorder.Generate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, expected, resp.Response)
+	}
+	_, err = recorder.Chat(ctx, interfaces.ChatRequest{Messages: []interfaces.ChatMessage{{Role: interfaces.ChatRoleUser, Content: "hi"}}})
+	require.NoError(t, err)
+	_, err = recorder.Embed(ctx, interfaces.EmbeddingRequest{Input: []string{"text"}})
+	require.NoError(t, err)
+
+	replay, err := NewReplayClient(path, log)
+	require.NoError(t, err)
+	assert.True(t, replay.IsHealthy(ctx))
+
+	// Streaming, the stage and option order do not change the key
+	request.Stream = true
+	request.Stage = ""
+	request.Options = map[string]interface{}{"num_predict": 2000, "temperature": 0.7}
+	for _, expected := range []string{"first", "second", "second"} {
+		resp, err := replay.Generate(ctx, request)
+		require.NoError(t, err)
+		assert.Equal(t, expected, resp.Response, "repeated requests replay in order, then repeat the last response")
+	}
+
+	chat, err := replay.Chat(ctx, interfaces.ChatRequest{Messages: []interfaces.ChatMessage{{Role: interfaces.ChatRoleUser, Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", chat.Message.Content)
+
+	_, err = replay.Embed(ctx, interfaces.EmbeddingRequest{Input: []string{"text"}})
+	require.NoError(t, err)
+
+	_, err = replay.Generate(ctx, interfaces.LLMRequest{Prompt: "something else"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded generate response in cassette")
+	assert.Contains(t, err.Error(), `"prompt":"something else"`)
+}
+
+func TestRecordingClientRecordsStreams(t *testing.T) {
+	ctx := context.Background()
+	log := logger.NewLogrusLogger("error")
+	path := filepath.Join(t.TempDir(), "llm.json")
+
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "Hel", "done": false}`,
+		`{"model": "llama3", "response": "lo", "done": true, "eval_count": 2}`,
+	}, nil)
+	defer server.Close()
+
+	recorder, err := NewRecordingClient(NewOllamaClientWithModel(server.URL, "llama3", log), path, log)
+	require.NoError(t, err)
+
+	var streamed []string
+	resp, err := Generate(WithChunkHandler(ctx, func(chunk interfaces.LLMChunk) {
+		streamed = append(streamed, chunk.Response)
+	}), recorder, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Response)
+	assert.Equal(t, []string{"Hel", "lo"}, streamed, "recording keeps the response streamed")
+
+	replay, err := NewReplayClient(path, log)
+	require.NoError(t, err)
+	resp, err = replay.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Response)
+	assert.Equal(t, 2, resp.EvalCount)
+}
+
+func TestNewReplayClientRequiresCassette(t *testing.T) {
+	_, err := NewReplayClient(filepath.Join(t.TempDir(), "missing.json"), logger.NewLogrusLogger("error"))
+	assert.ErrorContains(t, err, "failed to read cassette")
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaClientChat(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{
+			"model": "llama3.1",
+			"message": {"role": "assistant", "content": "", "tool_calls": [
+				{"function": {"name": "browser", "arguments": {"action": "navigate", "url": "https://go.dev"}}}
+			]},
+			"done": true,
+			"eval_count": 12
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3.1", logger.NewLogrusLogger("error"))
+	resp, err := client.Chat(context.Background(), interfaces.ChatRequest{
+		Messages: []interfaces.ChatMessage{
+			{Role: interfaces.ChatRoleSystem, Content: "You operate a browser."},
+			{Role: interfaces.ChatRoleUser, Content: "Open go.dev"},
+		},
+		Tools: []interfaces.Tool{{
+			Type: "function",
+			Function: interfaces.ToolFunction{
+				Name:        "browser",
+				Description: "Web browser automation",
+				Parameters:  map[string]interface{}{"type": "object"},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, false, received["stream"], "chat responses must not be streamed")
+	assert.Equal(t, "llama3.1", received["model"])
+	messages := received["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	assert.Equal(t, "system", messages[0].(map[string]interface{})["role"])
+	tools := received["tools"].([]interface{})
+	require.Len(t, tools, 1)
+	assert.Equal(t, map[string]interface{}{
+		"type": "function",
+		"function": map[string]interface{}{
+			"name":        "browser",
+			"description": "Web browser automation",
+			"parameters":  map[string]interface{}{"type": "object"},
+		},
+	}, tools[0])
+
+	assert.Equal(t, interfaces.ChatRoleAssistant, resp.Message.Role)
+	require.Len(t, resp.Message.ToolCalls, 1)
+	call := resp.Message.ToolCalls[0].Function
+	assert.Equal(t, "browser", call.Name)
+	assert.Equal(t, "https://go.dev", call.Arguments["url"])
+	assert.Equal(t, 12, resp.EvalCount)
+}
+
+func TestRoutedClientRoutesChat(t *testing.T) {
+	base := &recordingClient{}
+	client := NewRoutedClient(base, map[string]ModelRoute{
+		StagePlanning: {Model: "qwen2.5:14b"},
+	}, logger.NewLogrusLogger("error"))
+
+	_, err := client.Chat(context.Background(), interfaces.ChatRequest{Stage: StageReplanning})
+	require.NoError(t, err)
+
+	require.Len(t, base.chatRequests, 1)
+	assert.Equal(t, "qwen2.5:14b", base.chatRequests[0].Model)
+}
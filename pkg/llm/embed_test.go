@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaClientEmbed(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{
+			"model": "nomic-embed-text",
+			"embeddings": [[0.1, 0.2, 0.3], [0.4, 0.5, 0.6]],
+			"total_duration": 14143917,
+			"prompt_eval_count": 8
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	resp, err := client.Embed(context.Background(), interfaces.EmbeddingRequest{
+		Model: "nomic-embed-text",
+		Input: []string{"first", "second"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "nomic-embed-text", received["model"])
+	assert.Equal(t, []interface{}{"first", "second"}, received["input"])
+	assert.Equal(t, [][]float32{{0.1, 0.2, 0.3}, {0.4, 0.5, 0.6}}, resp.Embeddings)
+	assert.Equal(t, 3, resp.Dimensions)
+	assert.Equal(t, 8, resp.PromptEvalCount)
+
+	_, err = client.Embed(context.Background(), interfaces.EmbeddingRequest{})
+	assert.EqualError(t, err, "embedding request has no input")
+}
+
+func TestOllamaClientEmbedReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	_, err := client.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"text"}})
+	assert.EqualError(t, err, "Ollama returned status 404")
+
+	short := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "llama3", "embeddings": [[1, 2]]}`))
+	}))
+	defer short.Close()
+
+	client = NewOllamaClientWithModel(short.URL, "llama3", logger.NewLogrusLogger("error"))
+	_, err = client.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a", "b"}})
+	assert.EqualError(t, err, "Ollama returned 1 embeddings for 2 inputs")
+}
+
+func TestOpenAIClientEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		w.Write([]byte(`{
+			"model": "bge-small",
+			"data": [{"index": 1, "embedding": [0.3, 0.4]}, {"index": 0, "embedding": [0.1, 0.2]}],
+			"usage": {"prompt_tokens": 4}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL+"/v1", "", "bge-small", logger.NewLogrusLogger("error"))
+	resp, err := client.Embed(context.Background(), interfaces.EmbeddingRequest{Input: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, resp.Embeddings, "embeddings are returned in input order")
+	assert.Equal(t, 2, resp.Dimensions)
+	assert.Equal(t, 4, resp.PromptEvalCount)
+}
+
+func TestRoutedClientRoutesEmbeddings(t *testing.T) {
+	base := &recordingClient{}
+	client := NewRoutedClient(base, map[string]ModelRoute{
+		StageEmbedding: {Model: "nomic-embed-text"},
+	}, logger.NewLogrusLogger("error"))
+
+	_, err := client.Embed(context.Background(), interfaces.EmbeddingRequest{Stage: StageEmbedding, Input: []string{"text"}})
+	require.NoError(t, err)
+
+	require.Len(t, base.embedRequests, 1)
+	assert.Equal(t, "nomic-embed-text", base.embedRequests[0].Model)
+}
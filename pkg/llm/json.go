@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// codeFence matches a fenced code block, optionally tagged as json
+var codeFence = regexp.MustCompile("(?s)```(?:json|JSON)?\\s*\\n(.*?)```")
+
+// StripThinking removes <think> reasoning sections from a model response,
+// returning the answer of SplitReasoning
+func StripThinking(response string) string {
+	answer, _ := SplitReasoning(response)
+	return answer
+}
+
+// ExtractJSONObject returns the first complete JSON object in a model response,
+// ignoring reasoning sections, code fences and surrounding prose
+func ExtractJSONObject(response string) (string, error) {
+	response = StripThinking(response)
+
+	candidates := []string{response}
+	for _, match := range codeFence.FindAllStringSubmatch(response, -1) {
+		candidates = append(candidates, strings.TrimSpace(match[1]))
+	}
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, "{") && json.Valid([]byte(candidate)) {
+			return candidate, nil
+		}
+	}
+
+	// Fall back to scanning for a balanced object embedded in prose
+	for start := strings.IndexByte(response, '{'); start != -1; {
+		if end := matchingBrace(response, start); end != -1 {
+			candidate := response[start : end+1]
+			if json.Valid([]byte(candidate)) {
+				return candidate, nil
+			}
+		}
+
+		next := strings.IndexByte(response[start+1:], '{')
+		if next == -1 {
+			break
+		}
+		start += next + 1
+	}
+
+	if strings.Contains(response, "{") {
+		return "", fmt.Errorf("response contains no complete, valid JSON object")
+	}
+	return "", fmt.Errorf("no JSON object found in response")
+}
+
+// matchingBrace returns the index of the brace closing the object that starts
+// at start, skipping braces inside strings, or -1 if it is never closed
+func matchingBrace(s string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"plain", `{"a": 1}`, `{"a": 1}`},
+		{"thinking", "<think>maybe {\"b\": 2}?</think>\n{\"a\": 1}", `{"a": 1}`},
+		{"dangling think", "reasoning... </think> {\"a\": 1}", `{"a": 1}`},
+		{"fenced", "Here you go:\n```json\n{\"a\": \"}\"}\n```\nDone.", `{"a": "}"}`},
+		{"prose", `Sure! {"a": {"b": [1, 2]}} Hope this helps {`, `{"a": {"b": [1, 2]}}`},
+		{"skips invalid", `{not json} then {"a": 1}`, `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractJSONObject(tt.response)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExtractJSONObjectErrors(t *testing.T) {
+	_, err := ExtractJSONObject("no json here")
+	assert.EqualError(t, err, "no JSON object found in response")
+
+	_, err = ExtractJSONObject(`{"a": 1,`)
+	assert.EqualError(t, err, "response contains no complete, valid JSON object")
+}
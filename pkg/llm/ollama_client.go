@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -36,10 +37,198 @@ func NewOllamaClientWithModel(baseURL, defaultModel string, logger interfaces.Lo
 	}
 }
 
-// Generate sends a request to Ollama and returns the response
+// SetTimeout sets the timeout of requests whose responses are not streamed
+// (default 60s); streams are bounded by their context only
+func (c *OllamaClient) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// Generate sends a request to Ollama and returns the response. Streamed
+// requests are collected into a single response.
 func (c *OllamaClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	if request.Stream {
+		chunks, err := c.GenerateStream(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := CollectStream(chunks, nil)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return resp, err
+	}
+
+	resp, err := c.sendGenerate(ctx, c.httpClient, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Parse response
+	var llmResp interfaces.LLMResponse
+	if err := json.NewDecoder(resp.Body).Decode(&llmResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Models that think inline rather than in Ollama's thinking field
+	// prefix their answer with <think> sections
+	llmResp.Response, llmResp.Reasoning = separateReasoning(llmResp.Response, llmResp.Reasoning)
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":    llmResp.Model,
+		"response": llmResp.Response[:min(100, len(llmResp.Response))],
+		"done":     llmResp.Done,
+	}).Info("Received response from Ollama")
+
+	return &llmResp, nil
+}
+
+// GenerateStream sends a request to Ollama and returns the chunks of its
+// NDJSON response as they arrive
+func (c *OllamaClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	request.Stream = true
+
+	// Streams last as long as generation does; ctx bounds them instead of the
+	// client timeout
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := c.sendGenerate(ctx, &streamClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		var splitter reasoningSplitter
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var chunk struct {
+				interfaces.LLMChunk
+				Error string `json:"error"`
+			}
+			err := decoder.Decode(&chunk)
+			switch {
+			case ctx.Err() != nil:
+				err = ctx.Err()
+			case err == io.EOF:
+				err = fmt.Errorf("Ollama stream ended before the final chunk")
+			case err != nil:
+				err = fmt.Errorf("failed to decode stream chunk: %w", err)
+			case chunk.Error != "":
+				err = fmt.Errorf("Ollama stream failed: %s", chunk.Error)
+			}
+			if err != nil {
+				chunk.LLMChunk = interfaces.LLMChunk{Err: err}
+			} else {
+				chunk.LLMChunk = splitChunk(&splitter, chunk.LLMChunk)
+			}
+
+			select {
+			case chunks <- chunk.LLMChunk:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil || chunk.Done {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// Chat sends a conversation to Ollama's chat endpoint and returns the reply
+func (c *OllamaClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	c.logger.WithFields(map[string]interface{}{
+		"model":    request.Model,
+		"messages": len(request.Messages),
+		"tools":    len(request.Tools),
+	}).Info("Sending chat request to Ollama")
+
+	// Set default model if not specified
+	if request.Model == "" {
+		request.Model = c.defaultModel
+	}
+
+	// The chat endpoint streams unless told otherwise
+	payload := struct {
+		interfaces.ChatRequest
+		Stream bool `json:"stream"`
+	}{ChatRequest: request}
+
+	resp, err := c.send(ctx, c.httpClient, "/api/chat", payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var chatResp interfaces.ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	message := &chatResp.Message
+	message.Content, message.Reasoning = separateReasoning(message.Content, message.Reasoning)
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":      chatResp.Model,
+		"response":   chatResp.Message.Content[:min(100, len(chatResp.Message.Content))],
+		"tool_calls": len(chatResp.Message.ToolCalls),
+	}).Info("Received chat response from Ollama")
+
+	return &chatResp, nil
+}
+
+// Embed sends a batch of inputs to Ollama's embed endpoint and returns their
+// embeddings
+func (c *OllamaClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	c.logger.WithFields(map[string]interface{}{
+		"model":  request.Model,
+		"inputs": len(request.Input),
+	}).Info("Sending embedding request to Ollama")
+
+	if len(request.Input) == 0 {
+		return nil, fmt.Errorf("embedding request has no input")
+	}
+
+	// Set default model if not specified
+	if request.Model == "" {
+		request.Model = c.defaultModel
+	}
+
+	resp, err := c.send(ctx, c.httpClient, "/api/embed", request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var embedResp interfaces.EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(embedResp.Embeddings) != len(request.Input) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d inputs", len(embedResp.Embeddings), len(request.Input))
+	}
+	embedResp.Dimensions = len(embedResp.Embeddings[0])
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":      embedResp.Model,
+		"embeddings": len(embedResp.Embeddings),
+		"dimensions": embedResp.Dimensions,
+	}).Info("Received embeddings from Ollama")
+
+	return &embedResp, nil
+}
+
+// sendGenerate posts a request to Ollama's generate endpoint
+func (c *OllamaClient) sendGenerate(ctx context.Context, httpClient *http.Client, request interfaces.LLMRequest) (*http.Response, error) {
 	c.logger.WithFields(map[string]interface{}{
 		"model":  request.Model,
+		"stream": request.Stream,
 		"prompt": request.Prompt[:min(100, len(request.Prompt))],
 	}).Info("Sending request to Ollama")
 
@@ -48,6 +237,11 @@ func (c *OllamaClient) Generate(ctx context.Context, request interfaces.LLMReque
 		request.Model = c.defaultModel
 	}
 
+	return c.send(ctx, httpClient, "/api/generate", request)
+}
+
+// send posts a JSON request to an Ollama endpoint and checks the response status
+func (c *OllamaClient) send(ctx context.Context, httpClient *http.Client, path string, request interface{}) (*http.Response, error) {
 	// Prepare request body
 	reqBody, err := json.Marshal(request)
 	if err != nil {
@@ -55,7 +249,7 @@ func (c *OllamaClient) Generate(ctx context.Context, request interfaces.LLMReque
 	}
 
 	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -63,29 +257,17 @@ func (c *OllamaClient) Generate(ctx context.Context, request interfaces.LLMReque
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Ollama returned status %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, &StatusError{Server: "Ollama", StatusCode: resp.StatusCode}
 	}
 
-	// Parse response
-	var llmResp interfaces.LLMResponse
-	if err := json.NewDecoder(resp.Body).Decode(&llmResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	c.logger.WithFields(map[string]interface{}{
-		"model":    llmResp.Model,
-		"response": llmResp.Response[:min(100, len(llmResp.Response))],
-		"done":     llmResp.Done,
-	}).Info("Received response from Ollama")
-
-	return &llmResp, nil
+	return resp, nil
 }
 
 // IsHealthy checks if Ollama is running and accessible
@@ -0,0 +1,535 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// OpenAIClient implements the LLMClient interface for servers exposing the
+// OpenAI chat completions API, such as llama.cpp server and vLLM
+type OpenAIClient struct {
+	baseURL      string
+	apiKey       string
+	defaultModel string
+	httpClient   *http.Client
+	logger       interfaces.Logger
+}
+
+// NewOpenAIClient creates a client for the API at baseURL, which includes the
+// version prefix (e.g. http://localhost:8000/v1). The API key is optional;
+// when set it is sent as a bearer token.
+func NewOpenAIClient(baseURL, apiKey, defaultModel string, logger interfaces.Logger) *OpenAIClient {
+	return &OpenAIClient{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		apiKey:       apiKey,
+		defaultModel: defaultModel,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// SetTimeout sets the timeout of requests whose responses are not streamed
+// (default 60s); streams are bounded by their context only
+func (c *OpenAIClient) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// openAIMessage is a chat message in the OpenAI wire format. Servers hosting
+// thinking models report their reasoning as reasoning_content or reasoning.
+type openAIMessage struct {
+	Role             string           `json:"role"`
+	Content          string           `json:"content"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	Reasoning        string           `json:"reasoning,omitempty"`
+	ToolCalls        []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string           `json:"tool_call_id,omitempty"`
+}
+
+// reasoning returns the reasoning reported for the message, if any
+func (m openAIMessage) reasoning() string {
+	if m.ReasoningContent != "" {
+		return m.ReasoningContent
+	}
+	return m.Reasoning
+}
+
+// openAIToolCall is a tool call whose arguments are encoded as a JSON string
+type openAIToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIResponse is a chat completion or, when streaming, one of its chunks
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+		Delta   openAIMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *openAIError `json:"error"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+}
+
+// optionNames maps Ollama generation options to their OpenAI names. Options
+// mapped to "" configure the Ollama runtime and are not sent.
+var optionNames = map[string]string{
+	"num_predict": "max_tokens",
+	"num_ctx":     "",
+	"num_gpu":     "",
+	"num_thread":  "",
+	"keep_alive":  "",
+}
+
+// Generate sends the prompt as a single user message and returns the reply.
+// Streamed requests are collected into a single response.
+func (c *OpenAIClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	if request.Stream {
+		chunks, err := c.GenerateStream(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := CollectStream(chunks, nil)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return resp, err
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":  request.Model,
+		"prompt": request.Prompt[:min(100, len(request.Prompt))],
+	}).Info("Sending request to OpenAI-compatible server")
+
+	started := time.Now()
+	body := c.completionBody(request.Model, []openAIMessage{{Role: interfaces.ChatRoleUser, Content: request.Prompt}}, request.Format, request.Options)
+	completion, err := c.complete(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	message := completion.Choices[0].Message
+	llmResp := &interfaces.LLMResponse{
+		Model:    completion.Model,
+		Done:     true,
+		LLMStats: completion.stats(time.Since(started)),
+	}
+	llmResp.Response, llmResp.Reasoning = separateReasoning(message.Content, message.reasoning())
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":    llmResp.Model,
+		"response": llmResp.Response[:min(100, len(llmResp.Response))],
+	}).Info("Received response from OpenAI-compatible server")
+
+	return llmResp, nil
+}
+
+// GenerateStream sends the prompt as a single user message and returns the
+// chunks of the server-sent event stream as they arrive
+func (c *OpenAIClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	c.logger.WithFields(map[string]interface{}{
+		"model":  request.Model,
+		"stream": true,
+		"prompt": request.Prompt[:min(100, len(request.Prompt))],
+	}).Info("Sending request to OpenAI-compatible server")
+
+	body := c.completionBody(request.Model, []openAIMessage{{Role: interfaces.ChatRoleUser, Content: request.Prompt}}, request.Format, request.Options)
+	body["stream"] = true
+	body["stream_options"] = map[string]interface{}{"include_usage": true}
+
+	// Streams last as long as generation does; ctx bounds them instead of the
+	// client timeout
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	started := time.Now()
+	resp, err := c.send(ctx, &streamClient, "/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		emit := func(chunk interfaces.LLMChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var model string
+		var stats interfaces.LLMStats
+		var splitter reasoningSplitter
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				stats.TotalDuration = time.Since(started)
+				emit(splitChunk(&splitter, interfaces.LLMChunk{Model: model, Done: true, LLMStats: stats}))
+				return
+			}
+
+			var event openAIResponse
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				emit(interfaces.LLMChunk{Err: fmt.Errorf("failed to decode stream chunk: %w", err)})
+				return
+			}
+			if event.Error != nil {
+				emit(interfaces.LLMChunk{Err: fmt.Errorf("OpenAI-compatible stream failed: %s", event.Error.Message)})
+				return
+			}
+
+			if event.Model != "" {
+				model = event.Model
+			}
+			if event.Usage != nil {
+				stats = event.stats(0)
+			}
+			if len(event.Choices) == 0 {
+				continue
+			}
+			delta := event.Choices[0].Delta
+			chunk := splitChunk(&splitter, interfaces.LLMChunk{Model: model, Response: delta.Content, Reasoning: delta.reasoning()})
+			if chunk.Response == "" && chunk.Reasoning == "" {
+				continue
+			}
+			if !emit(chunk) {
+				return
+			}
+		}
+
+		err := scanner.Err()
+		switch {
+		case ctx.Err() != nil:
+			err = ctx.Err()
+		case err == nil:
+			err = fmt.Errorf("OpenAI-compatible stream ended before the final chunk")
+		default:
+			err = fmt.Errorf("failed to read stream: %w", err)
+		}
+		emit(interfaces.LLMChunk{Err: err})
+	}()
+
+	return chunks, nil
+}
+
+// Chat sends a conversation to the chat completions endpoint and returns the reply
+func (c *OpenAIClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	c.logger.WithFields(map[string]interface{}{
+		"model":    request.Model,
+		"messages": len(request.Messages),
+		"tools":    len(request.Tools),
+	}).Info("Sending chat request to OpenAI-compatible server")
+
+	messages := make([]openAIMessage, len(request.Messages))
+	for i, message := range request.Messages {
+		converted, err := toOpenAIMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		messages[i] = converted
+	}
+
+	started := time.Now()
+	body := c.completionBody(request.Model, messages, request.Format, request.Options)
+	if len(request.Tools) > 0 {
+		body["tools"] = request.Tools
+	}
+	completion, err := c.complete(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := fromOpenAIMessage(completion.Choices[0].Message)
+	if err != nil {
+		return nil, err
+	}
+	chatResp := &interfaces.ChatResponse{
+		Model:    completion.Model,
+		Message:  message,
+		Done:     true,
+		LLMStats: completion.stats(time.Since(started)),
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":      chatResp.Model,
+		"response":   message.Content[:min(100, len(message.Content))],
+		"tool_calls": len(message.ToolCalls),
+	}).Info("Received chat response from OpenAI-compatible server")
+
+	return chatResp, nil
+}
+
+// Embed sends a batch of inputs to the embeddings endpoint and returns their
+// embeddings
+func (c *OpenAIClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	c.logger.WithFields(map[string]interface{}{
+		"model":  request.Model,
+		"inputs": len(request.Input),
+	}).Info("Sending embedding request to OpenAI-compatible server")
+
+	if len(request.Input) == 0 {
+		return nil, fmt.Errorf("embedding request has no input")
+	}
+
+	// Set default model if not specified
+	if request.Model == "" {
+		request.Model = c.defaultModel
+	}
+
+	started := time.Now()
+	resp, err := c.send(ctx, c.httpClient, "/embeddings", map[string]interface{}{
+		"model": request.Model,
+		"input": request.Input,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		openAIResponse
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(decoded.Data) != len(request.Input) {
+		return nil, fmt.Errorf("OpenAI-compatible server returned %d embeddings for %d inputs", len(decoded.Data), len(request.Input))
+	}
+
+	embedResp := &interfaces.EmbeddingResponse{
+		Model:      decoded.Model,
+		Embeddings: make([][]float32, len(decoded.Data)),
+		LLMStats:   decoded.stats(time.Since(started)),
+	}
+	for i, item := range decoded.Data {
+		// Embeddings are listed with the index of their input
+		index := item.Index
+		if index < 0 || index >= len(decoded.Data) {
+			index = i
+		}
+		embedResp.Embeddings[index] = item.Embedding
+	}
+	embedResp.Dimensions = len(embedResp.Embeddings[0])
+
+	c.logger.WithFields(map[string]interface{}{
+		"model":      embedResp.Model,
+		"embeddings": len(embedResp.Embeddings),
+		"dimensions": embedResp.Dimensions,
+	}).Info("Received embeddings from OpenAI-compatible server")
+
+	return embedResp, nil
+}
+
+// IsHealthy checks if the server is running and accepts the API key
+func (c *OpenAIClient) IsHealthy(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		c.logger.WithField("error", err).Error("Failed to create health check request")
+		return false
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.WithField("error", err).Error("OpenAI-compatible server health check failed")
+		return false
+	}
+	defer resp.Body.Close()
+
+	healthy := resp.StatusCode == http.StatusOK
+	c.logger.WithField("healthy", healthy).Debug("OpenAI-compatible server health check completed")
+
+	return healthy
+}
+
+// completionBody builds a chat completions request, translating the format
+// and Ollama generation options
+func (c *OpenAIClient) completionBody(model string, messages []openAIMessage, format interface{}, options map[string]interface{}) map[string]interface{} {
+	body := make(map[string]interface{}, len(options)+4)
+	for key, value := range options {
+		if name, ok := optionNames[key]; ok {
+			if name == "" {
+				continue
+			}
+			key = name
+		}
+		body[key] = value
+	}
+
+	// Set default model if not specified
+	if model == "" {
+		model = c.defaultModel
+	}
+	body["model"] = model
+	body["messages"] = messages
+	body["stream"] = false
+
+	switch format := format.(type) {
+	case nil:
+	case string:
+		if format == "json" {
+			body["response_format"] = map[string]interface{}{"type": "json_object"}
+		}
+	default:
+		body["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   "response",
+				"schema": format,
+			},
+		}
+	}
+
+	return body
+}
+
+// complete sends a non-streamed completion request and decodes the reply
+func (c *OpenAIClient) complete(ctx context.Context, body map[string]interface{}) (*openAIResponse, error) {
+	resp, err := c.send(ctx, c.httpClient, "/chat/completions", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var completion openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI-compatible server returned no choices")
+	}
+	return &completion, nil
+}
+
+// send posts a JSON request to an API endpoint and checks the response status
+func (c *OpenAIClient) send(ctx context.Context, httpClient *http.Client, path string, request interface{}) (*http.Response, error) {
+	reqBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.authorize(httpReq)
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to OpenAI-compatible server: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var failure struct {
+			Error *openAIError `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(data, &failure) == nil && failure.Error != nil && failure.Error.Message != "" {
+			return nil, &StatusError{Server: "OpenAI-compatible server", StatusCode: resp.StatusCode, Message: failure.Error.Message}
+		}
+		return nil, &StatusError{Server: "OpenAI-compatible server", StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+// authorize adds the API key to a request
+func (c *OpenAIClient) authorize(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
+// stats converts the reported token usage
+func (r *openAIResponse) stats(elapsed time.Duration) interfaces.LLMStats {
+	stats := interfaces.LLMStats{TotalDuration: elapsed}
+	if r.Usage != nil {
+		stats.PromptEvalCount = r.Usage.PromptTokens
+		stats.EvalCount = r.Usage.CompletionTokens
+	}
+	return stats
+}
+
+// toOpenAIMessage encodes the arguments of the message's tool calls as JSON strings
+func toOpenAIMessage(message interfaces.ChatMessage) (openAIMessage, error) {
+	converted := openAIMessage{
+		Role:       message.Role,
+		Content:    message.Content,
+		ToolCallID: message.ToolCallID,
+	}
+	for _, call := range message.ToolCalls {
+		arguments, err := json.Marshal(call.Function.Arguments)
+		if err != nil {
+			return openAIMessage{}, fmt.Errorf("failed to encode arguments of tool call %s: %w", call.Function.Name, err)
+		}
+		toolCall := openAIToolCall{ID: call.ID, Type: "function"}
+		toolCall.Function.Name = call.Function.Name
+		toolCall.Function.Arguments = string(arguments)
+		converted.ToolCalls = append(converted.ToolCalls, toolCall)
+	}
+	return converted, nil
+}
+
+// fromOpenAIMessage decodes the JSON string arguments of the message's tool calls
+func fromOpenAIMessage(message openAIMessage) (interfaces.ChatMessage, error) {
+	converted := interfaces.ChatMessage{
+		Role:       message.Role,
+		ToolCallID: message.ToolCallID,
+	}
+	converted.Content, converted.Reasoning = separateReasoning(message.Content, message.reasoning())
+	for _, call := range message.ToolCalls {
+		var arguments map[string]interface{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &arguments); err != nil {
+				return interfaces.ChatMessage{}, fmt.Errorf("failed to decode arguments of tool call %s: %w", call.Function.Name, err)
+			}
+		}
+		converted.ToolCalls = append(converted.ToolCalls, interfaces.ToolCall{
+			ID: call.ID,
+			Function: interfaces.ToolCallFunction{
+				Name:      call.Function.Name,
+				Arguments: arguments,
+			},
+		})
+	}
+	return converted, nil
+}
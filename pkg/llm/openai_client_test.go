@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClientGenerate(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{
+			"model": "qwen2.5-7b",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "{\"ok\": true}"}, "finish_reason": "stop"}],
+			"usage": {"prompt_tokens": 9, "completion_tokens": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL+"/v1/", "secret", "qwen2.5-7b", logger.NewLogrusLogger("error"))
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{
+		Prompt:  "Reply with JSON",
+		Format:  "json",
+		Options: map[string]interface{}{"temperature": 0.2, "num_predict": 200, "num_ctx": 8192},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "qwen2.5-7b", received["model"])
+	assert.Equal(t, false, received["stream"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"role": "user", "content": "Reply with JSON"}}, received["messages"])
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, received["response_format"])
+	assert.Equal(t, 0.2, received["temperature"])
+	assert.Equal(t, float64(200), received["max_tokens"])
+	assert.NotContains(t, received, "num_ctx", "Ollama runtime options are not sent")
+
+	assert.Equal(t, `{"ok": true}`, resp.Response)
+	assert.True(t, resp.Done)
+	assert.Equal(t, 9, resp.PromptEvalCount)
+	assert.Equal(t, 5, resp.EvalCount)
+}
+
+func TestOpenAIClientChatWithTools(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Write([]byte(`{
+			"model": "llama-3.1-8b",
+			"choices": [{"message": {"role": "assistant", "content": "", "tool_calls": [
+				{"id": "call_2", "type": "function", "function": {"name": "browser", "arguments": "{\"action\": \"click\", \"selector\": \"#more\"}"}}
+			]}}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", "llama-3.1-8b", logger.NewLogrusLogger("error"))
+	resp, err := client.Chat(context.Background(), interfaces.ChatRequest{
+		Messages: []interfaces.ChatMessage{
+			{Role: interfaces.ChatRoleUser, Content: "Open go.dev"},
+			{Role: interfaces.ChatRoleAssistant, ToolCalls: []interfaces.ToolCall{{
+				ID:       "call_1",
+				Function: interfaces.ToolCallFunction{Name: "browser", Arguments: map[string]interface{}{"action": "navigate"}},
+			}}},
+			{Role: interfaces.ChatRoleTool, Content: "navigated", ToolName: "browser", ToolCallID: "call_1"},
+		},
+		Tools: []interfaces.Tool{{Type: "function", Function: interfaces.ToolFunction{Name: "browser", Description: "Web browser automation"}}},
+	})
+	require.NoError(t, err)
+
+	messages := received["messages"].([]interface{})
+	require.Len(t, messages, 3)
+	call := messages[1].(map[string]interface{})["tool_calls"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "call_1", call["id"])
+	assert.Equal(t, `{"action":"navigate"}`, call["function"].(map[string]interface{})["arguments"], "arguments are sent as a JSON string")
+	assert.Equal(t, "call_1", messages[2].(map[string]interface{})["tool_call_id"])
+	assert.Len(t, received["tools"], 1)
+
+	require.Len(t, resp.Message.ToolCalls, 1)
+	assert.Equal(t, "call_2", resp.Message.ToolCalls[0].ID)
+	assert.Equal(t, map[string]interface{}{"action": "click", "selector": "#more"}, resp.Message.ToolCalls[0].Function.Arguments)
+}
+
+func TestOpenAIClientStreamsChunks(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"model": "m", "choices": [{"delta": {"role": "assistant", "content": ""}}]}`,
+			`{"model": "m", "choices": [{"delta": {"content": "Hel"}}]}`,
+			`{"model": "m", "choices": [{"delta": {"content": "lo"}, "finish_reason": "stop"}]}`,
+			`{"model": "m", "choices": [], "usage": {"prompt_tokens": 3, "completion_tokens": 2}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", "m", logger.NewLogrusLogger("error"))
+	chunks, err := client.GenerateStream(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+
+	var collected []interfaces.LLMChunk
+	for chunk := range chunks {
+		collected = append(collected, chunk)
+	}
+
+	require.Len(t, collected, 3)
+	assert.Equal(t, "Hel", collected[0].Response)
+	assert.Equal(t, "lo", collected[1].Response)
+	assert.True(t, collected[2].Done)
+	assert.Equal(t, 2, collected[2].EvalCount)
+	assert.Equal(t, true, received["stream"])
+}
+
+func TestOpenAIClientReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"message": "model not found"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "wrong", "m", logger.NewLogrusLogger("error"))
+	assert.False(t, client.IsHealthy(context.Background()), "rejected API keys make the server unhealthy")
+
+	_, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	assert.EqualError(t, err, "OpenAI-compatible server returned status 400: model not found")
+
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\": [{\"delta\": {\"content\": \"partial\"}}]}\n\n")
+	}))
+	defer truncated.Close()
+
+	client = NewOpenAIClient(truncated.URL, "", "m", logger.NewLogrusLogger("error"))
+	_, err = client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi", Stream: true})
+	assert.EqualError(t, err, "OpenAI-compatible stream ended before the final chunk")
+}
+
+func TestOpenAIClientIsHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		w.Write([]byte(`{"object": "list", "data": [{"id": "m"}]}`))
+	}))
+	client := NewOpenAIClient(server.URL+"/v1", "", "m", logger.NewLogrusLogger("error"))
+	assert.True(t, client.IsHealthy(context.Background()))
+
+	server.Close()
+	assert.False(t, client.IsHealthy(context.Background()))
+}
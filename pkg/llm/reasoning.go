@@ -0,0 +1,272 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// thinkBlock matches reasoning sections emitted by thinking models such as deepseek-r1
+var thinkBlock = regexp.MustCompile(`(?s)<think>(.*?)</think>`)
+
+// SplitReasoning separates the <think> reasoning sections of a model response
+// from its answer. Some models omit the opening tag, so text before a
+// </think> is reasoning as well when that is the first tag of the response.
+// A section left unclosed is only reasoning when it starts the response, as
+// the response was then cut off while thinking; elsewhere the text is kept.
+func SplitReasoning(response string) (answer, reasoning string) {
+	var sections []string
+	if closeIdx := strings.Index(response, thinkClose); closeIdx != -1 {
+		if openIdx := strings.Index(response, thinkOpen); openIdx == -1 || openIdx > closeIdx {
+			sections = append(sections, response[:closeIdx])
+			response = response[closeIdx+len(thinkClose):]
+		}
+	}
+
+	for _, match := range thinkBlock.FindAllStringSubmatch(response, -1) {
+		sections = append(sections, match[1])
+	}
+	answer = thinkBlock.ReplaceAllString(response, "")
+
+	// Any other </think> closes nothing; drop the tag but keep the text
+	answer = strings.ReplaceAll(answer, thinkClose, "")
+
+	if trimmed := strings.TrimSpace(answer); strings.HasPrefix(trimmed, thinkOpen) {
+		sections = append(sections, trimmed[len(thinkOpen):])
+		answer = ""
+	}
+
+	return strings.TrimSpace(answer), joinReasoning(sections)
+}
+
+// separateReasoning moves the inline <think> sections of a complete answer to
+// the reasoning the server reported separately, if any
+func separateReasoning(answer, reasoning string) (string, string) {
+	if !strings.Contains(answer, thinkOpen) && !strings.Contains(answer, thinkClose) {
+		return answer, reasoning
+	}
+	answer, inline := SplitReasoning(answer)
+	return answer, joinReasoning([]string{reasoning, inline})
+}
+
+// joinReasoning joins the non-empty reasoning sections of a response
+func joinReasoning(sections []string) string {
+	var kept []string
+	for _, section := range sections {
+		if section = strings.TrimSpace(section); section != "" {
+			kept = append(kept, section)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// reasoningSplitter separates <think> sections from streamed text. Text that
+// may be the start of a tag is held back until the next piece arrives. Text
+// already passed on cannot be taken back, so a </think> without an opening
+// tag is left in the answer for SplitReasoning to handle once the stream is
+// collected, while text after a <think> goes to the reasoning wherever the
+// tag appears, since whether it is closed is only known at the end.
+type reasoningSplitter struct {
+	thinking bool
+	pending  string
+}
+
+// split returns the answer and reasoning text of the next piece of a stream
+func (s *reasoningSplitter) split(text string) (answer, reasoning string) {
+	var answerText, reasoningText strings.Builder
+	text = s.pending + text
+	s.pending = ""
+
+	for text != "" {
+		tag, out := thinkOpen, &answerText
+		if s.thinking {
+			tag, out = thinkClose, &reasoningText
+		}
+
+		if idx := strings.Index(text, tag); idx != -1 {
+			out.WriteString(text[:idx])
+			text = text[idx+len(tag):]
+			s.thinking = !s.thinking
+			continue
+		}
+
+		held := partialTag(text, tag)
+		out.WriteString(text[:len(text)-held])
+		s.pending = text[len(text)-held:]
+		break
+	}
+
+	return answerText.String(), reasoningText.String()
+}
+
+// flush returns the text held back at the end of a stream
+func (s *reasoningSplitter) flush() (answer, reasoning string) {
+	pending := s.pending
+	s.pending = ""
+	if s.thinking {
+		return "", pending
+	}
+	return pending, ""
+}
+
+// splitChunk moves the <think> sections of a streamed chunk's text to its
+// reasoning, flushing the splitter with the final chunk
+func splitChunk(splitter *reasoningSplitter, chunk interfaces.LLMChunk) interfaces.LLMChunk {
+	answer, reasoning := splitter.split(chunk.Response)
+	if chunk.Done {
+		heldAnswer, heldReasoning := splitter.flush()
+		answer += heldAnswer
+		reasoning += heldReasoning
+	}
+	chunk.Response = answer
+	chunk.Reasoning += reasoning
+	return chunk
+}
+
+// partialTag returns the length of the longest suffix of text that is a proper
+// prefix of tag
+func partialTag(text, tag string) int {
+	for n := min(len(text), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+type reasoningTaskKey struct{}
+
+// WithReasoningTask returns a context whose reasoning saved by a
+// ReasoningArchive is grouped under a task
+func WithReasoningTask(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, reasoningTaskKey{}, taskID)
+}
+
+// reasoningTaskFrom returns the task set by WithReasoningTask, or ""
+func reasoningTaskFrom(ctx context.Context) string {
+	taskID, _ := ctx.Value(reasoningTaskKey{}).(string)
+	return taskID
+}
+
+// ReasoningArchive saves the reasoning of every response to a text file for
+// debugging. Files are grouped in a directory per task (WithReasoningTask),
+// or per stage for requests made outside a task, and failures to save are
+// only logged.
+type ReasoningArchive struct {
+	client interfaces.LLMClient
+	dir    string
+	logger interfaces.Logger
+}
+
+// NewReasoningArchive wraps client, saving reasoning below dir
+func NewReasoningArchive(client interfaces.LLMClient, dir string, logger interfaces.Logger) *ReasoningArchive {
+	return &ReasoningArchive{
+		client: client,
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+// Generate forwards the request and saves the reasoning of its response
+func (c *ReasoningArchive) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	resp, err := c.client.Generate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, request.Stage, resp.Reasoning)
+	return resp, nil
+}
+
+// GenerateStream streams the response and saves its reasoning once the
+// stream completes
+func (c *ReasoningArchive) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	upstream, err := Stream(ctx, c.client, request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+		var reasoning strings.Builder
+		for chunk := range upstream {
+			reasoning.WriteString(chunk.Reasoning)
+			if chunk.Done {
+				c.save(ctx, request.Stage, reasoning.String())
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// Chat forwards the request and saves the reasoning of its reply
+func (c *ReasoningArchive) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	resp, err := c.client.Chat(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.save(ctx, request.Stage, resp.Message.Reasoning)
+	return resp, nil
+}
+
+// Embed forwards the request; embeddings have no reasoning
+func (c *ReasoningArchive) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	return c.client.Embed(ctx, request)
+}
+
+// IsHealthy reports whether the wrapped client is healthy
+func (c *ReasoningArchive) IsHealthy(ctx context.Context) bool {
+	return c.client.IsHealthy(ctx)
+}
+
+// save writes reasoning to <dir>/<task or stage>/<time>-<stage>.txt
+func (c *ReasoningArchive) save(ctx context.Context, stage, reasoning string) {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return
+	}
+	if stage == "" {
+		stage = stageOther
+	}
+
+	group := reasoningTaskFrom(ctx)
+	if group == "" {
+		group = stage
+	}
+	dir := filepath.Join(c.dir, group)
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.txt", time.Now().Format("20060102-150405.000000000"), stage))
+
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(reasoning+"\n"), 0644)
+	}
+	if err != nil {
+		c.logger.WithFields(map[string]interface{}{
+			"path":  path,
+			"error": err.Error(),
+		}).Warn("Failed to save LLM reasoning")
+		return
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"stage": stage,
+		"path":  path,
+	}).Debug("LLM reasoning saved")
+}
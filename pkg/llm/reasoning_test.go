@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitReasoning(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		answer    string
+		reasoning string
+	}{
+		{"plain", `{"a": 1}`, `{"a": 1}`, ""},
+		{"thinking", "<think>\nmaybe {\"b\": 2}?\n</think>\n\n{\"a\": 1}", `{"a": 1}`, `maybe {"b": 2}?`},
+		{"several sections", "<think>one</think>A<think>two</think>B", "AB", "one\n\ntwo"},
+		{"dangling close", "reasoning... </think> {\"a\": 1}", `{"a": 1}`, "reasoning..."},
+		{"stray close after a section", "<think>one</think>A</think>B", "AB", "one"},
+		{"unclosed", "<think>cut off", "", "cut off"},
+		{"unclosed after a section", "<think>one</think>\n<think>cut off", "", "one\n\ncut off"},
+		{"unclosed in the answer", "answer <think>cut off", "answer <think>cut off", ""},
+		{"empty section", "<think>\n\n</think>done", "done", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, reasoning := SplitReasoning(tt.response)
+			assert.Equal(t, tt.answer, answer)
+			assert.Equal(t, tt.reasoning, reasoning)
+		})
+	}
+}
+
+func TestOllamaClientSeparatesReasoning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "deepseek-r1", "response": "<think>plan it</think>\n{\"tasks\": []}", "done": true}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "deepseek-r1", logger.NewLogrusLogger("error"))
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"tasks": []}`, resp.Response)
+	assert.Equal(t, "plan it", resp.Reasoning)
+}
+
+func TestOllamaClientSeparatesStreamedReasoning(t *testing.T) {
+	// Tags are split across chunks
+	server := newStreamingServer(t, []string{
+		`{"model": "deepseek-r1", "response": "<thi", "done": false}`,
+		`{"model": "deepseek-r1", "response": "nk>let me", "done": false}`,
+		`{"model": "deepseek-r1", "response": " think</", "done": false}`,
+		`{"model": "deepseek-r1", "response": "think>Hel", "done": false}`,
+		`{"model": "deepseek-r1", "response": "lo <", "done": false}`,
+		`{"model": "deepseek-r1", "response": "", "done": true}`,
+	}, nil)
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "deepseek-r1", logger.NewLogrusLogger("error"))
+	var answer, reasoning string
+	ctx := WithChunkHandler(context.Background(), func(chunk interfaces.LLMChunk) {
+		answer += chunk.Response
+		reasoning += chunk.Reasoning
+	})
+
+	resp, err := Generate(ctx, client, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello <", answer, "held back text is released with the final chunk")
+	assert.Equal(t, "let me think", reasoning)
+	assert.Equal(t, "Hello <", resp.Response)
+	assert.Equal(t, "let me think", resp.Reasoning)
+}
+
+func TestCollectStreamSeparatesDanglingReasoning(t *testing.T) {
+	chunks := make(chan interfaces.LLMChunk, 2)
+	chunks <- interfaces.LLMChunk{Response: "hmm, json</think>"}
+	chunks <- interfaces.LLMChunk{Response: `{"a": 1}`, Done: true}
+	close(chunks)
+
+	resp, err := CollectStream(chunks, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, resp.Response)
+	assert.Equal(t, "hmm, json", resp.Reasoning)
+}
+
+func TestOpenAIClientSeparatesReasoning(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model": "m", "choices": [{"message": {"role": "assistant", "reasoning_content": "because", "content": "<think>also</think>yes"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL, "", "m", logger.NewLogrusLogger("error"))
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "yes", resp.Response)
+	assert.Equal(t, "because\n\nalso", resp.Reasoning)
+
+	chat, err := client.Chat(context.Background(), interfaces.ChatRequest{Messages: []interfaces.ChatMessage{{Role: interfaces.ChatRoleUser, Content: "hi"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "yes", chat.Message.Content)
+	assert.Equal(t, "because\n\nalso", chat.Message.Reasoning)
+}
+
+// reasoningClient answers every request with fixed reasoning
+type reasoningClient struct {
+	recordingClient
+}
+
+func (c *reasoningClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	return &interfaces.LLMResponse{Response: "ok", Reasoning: "thought about " + request.Prompt, Done: true}, nil
+}
+
+func TestReasoningArchiveSavesReasoningByTask(t *testing.T) {
+	dir := t.TempDir()
+	client := NewReasoningArchive(&reasoningClient{}, dir, logger.NewLogrusLogger("error"))
+	ctx := context.Background()
+
+	resp, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "the plan", Stage: StagePlanning})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+	_, err = client.Generate(WithReasoningTask(ctx, "task-1"), interfaces.LLMRequest{Prompt: "the page", Stage: StageAnalysis})
+	require.NoError(t, err)
+
+	// Streams are saved once they complete
+	chunks, err := client.GenerateStream(WithReasoningTask(ctx, "task-1"), interfaces.LLMRequest{Prompt: "more", Stage: StageAnalysis})
+	require.NoError(t, err)
+	_, err = CollectStream(chunks, nil)
+	require.NoError(t, err)
+
+	planning, err := filepath.Glob(filepath.Join(dir, StagePlanning, "*-planning.txt"))
+	require.NoError(t, err)
+	require.Len(t, planning, 1)
+	content, err := os.ReadFile(planning[0])
+	require.NoError(t, err)
+	assert.Equal(t, "thought about the plan\n", string(content))
+
+	task, err := filepath.Glob(filepath.Join(dir, "task-1", "*-analysis.txt"))
+	require.NoError(t, err)
+	assert.Len(t, task, 2)
+}
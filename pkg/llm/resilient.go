@@ -0,0 +1,520 @@
+package llm
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// StatusError reports an unsuccessful HTTP status returned by an LLM server
+type StatusError struct {
+	Server     string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s returned status %d: %s", e.Server, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s returned status %d", e.Server, e.StatusCode)
+}
+
+// IsRejected reports whether the LLM server rejected a request with a 4xx
+// status, as servers without structured output support do for a Format.
+// Rate limiting (429) is not a rejection of the request itself.
+func IsRejected(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && status.StatusCode >= 400 && status.StatusCode < 500 &&
+		status.StatusCode != http.StatusTooManyRequests
+}
+
+var (
+	// ErrCircuitOpen is returned without contacting the server while the
+	// circuit breaker is open
+	ErrCircuitOpen = errors.New("LLM server is unavailable: circuit breaker is open")
+
+	// ErrQueueFull is returned when a request finds the queue full
+	ErrQueueFull = errors.New("LLM request queue is full")
+
+	// ErrRetriesExhausted matches the errors of requests that failed every
+	// attempt, so callers can avoid retrying them once more
+	ErrRetriesExhausted = errors.New("LLM request retries exhausted")
+)
+
+// exhaustedError reports the last failure of a request that was retried
+type exhaustedError struct {
+	attempts int
+	err      error
+}
+
+func (e *exhaustedError) Error() string {
+	return fmt.Sprintf("LLM request failed after %d attempts: %v", e.attempts, e.err)
+}
+
+func (e *exhaustedError) Unwrap() error { return e.err }
+
+func (e *exhaustedError) Is(target error) bool { return target == ErrRetriesExhausted }
+
+// Request priorities; queued requests of higher priority are sent first
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose LLM requests are queued with the given
+// priority by a ResilientClient (default PriorityNormal)
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func priorityFrom(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityKey{}).(int)
+	return priority
+}
+
+// ResilienceConfig configures a ResilientClient; zero fields take their defaults
+type ResilienceConfig struct {
+	// MaxAttempts bounds the attempts per request including the first
+	// (default 3); 1 disables retries
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, doubling with every
+	// further retry up to MaxBackoff (defaults 1s and 15s)
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// FailureThreshold consecutive failed attempts open the circuit breaker
+	// (default 5). Requests then fail immediately for OpenDuration (default
+	// 30s), after which a single trial request decides whether it closes.
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	// MaxConcurrent bounds the requests sent at once (default 2); up to
+	// MaxQueued further requests wait by priority (default 100)
+	MaxConcurrent int
+	MaxQueued     int
+}
+
+func (c ResilienceConfig) withDefaults() ResilienceConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 3
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 15 * time.Second
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.OpenDuration <= 0 {
+		c.OpenDuration = 30 * time.Second
+	}
+	if c.MaxConcurrent <= 0 {
+		c.MaxConcurrent = 2
+	}
+	if c.MaxQueued <= 0 {
+		c.MaxQueued = 100
+	}
+	return c
+}
+
+// ResilientClient protects an LLM server from its callers and them from its
+// outages: requests wait in a bounded priority queue, attempts failing with a
+// server error or a connection problem are retried with backoff, and a
+// circuit breaker fails requests fast while the server is down.
+type ResilientClient struct {
+	client  interfaces.LLMClient
+	config  ResilienceConfig
+	queue   *requestQueue
+	breaker *circuitBreaker
+	logger  interfaces.Logger
+}
+
+// NewResilientClient wraps client with the given configuration
+func NewResilientClient(client interfaces.LLMClient, config ResilienceConfig, logger interfaces.Logger) *ResilientClient {
+	config = config.withDefaults()
+	return &ResilientClient{
+		client:  client,
+		config:  config,
+		queue:   newRequestQueue(config.MaxConcurrent, config.MaxQueued),
+		breaker: &circuitBreaker{threshold: config.FailureThreshold, openFor: config.OpenDuration, now: time.Now},
+		logger:  logger,
+	}
+}
+
+// Generate sends the request once a queue slot is free, retrying failed attempts
+func (c *ResilientClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	var resp *interfaces.LLMResponse
+	err := c.send(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.Generate(ctx, request)
+		return err
+	})
+	return resp, err
+}
+
+// GenerateStream starts the stream once a queue slot is free, retrying
+// failed attempts to start it. The slot is held until the stream ends. Only
+// starting the stream is retried; a stream that breaks off after it started
+// is not resumed.
+func (c *ResilientClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	var upstream <-chan interfaces.LLMChunk
+	release, err := c.do(ctx, func(ctx context.Context) error {
+		var err error
+		upstream, err = Stream(ctx, c.client, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+		defer release()
+
+		for chunk := range upstream {
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// Chat sends the request once a queue slot is free, retrying failed attempts
+func (c *ResilientClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	var resp *interfaces.ChatResponse
+	err := c.send(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.Chat(ctx, request)
+		return err
+	})
+	return resp, err
+}
+
+// Embed sends the request once a queue slot is free, retrying failed attempts
+func (c *ResilientClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	var resp *interfaces.EmbeddingResponse
+	err := c.send(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.Embed(ctx, request)
+		return err
+	})
+	return resp, err
+}
+
+// IsHealthy checks the wrapped client directly, bypassing queue and breaker
+func (c *ResilientClient) IsHealthy(ctx context.Context) bool {
+	return c.client.IsHealthy(ctx)
+}
+
+// send runs a call through do and frees its queue slot
+func (c *ResilientClient) send(ctx context.Context, call func(ctx context.Context) error) error {
+	release, err := c.do(ctx, call)
+	if err != nil {
+		return err
+	}
+	release()
+	return nil
+}
+
+// do runs a call through the queue and the circuit breaker, retrying failed
+// attempts. A successful call keeps its queue slot until release is called.
+func (c *ResilientClient) do(ctx context.Context, call func(ctx context.Context) error) (release func(), err error) {
+	priority := priorityFrom(ctx)
+
+	for attempt := 1; ; attempt++ {
+		if c.breaker.isOpen() {
+			return nil, ErrCircuitOpen
+		}
+		if err := c.queue.acquire(ctx, priority); err != nil {
+			return nil, err
+		}
+		if !c.breaker.allow() {
+			c.queue.release()
+			return nil, ErrCircuitOpen
+		}
+
+		err := call(ctx)
+		switch {
+		case err == nil:
+			c.breaker.success()
+			return c.queue.release, nil
+		case ctx.Err() != nil:
+			c.queue.release()
+			c.breaker.abandon()
+			return nil, err
+		case !isRetryable(err) && !isTimeout(err):
+			// The server answered, so it is up
+			c.queue.release()
+			c.breaker.success()
+			return nil, err
+		}
+
+		c.queue.release()
+		if c.breaker.failure() {
+			c.logger.WithFields(map[string]interface{}{
+				"error":    err.Error(),
+				"open_for": c.config.OpenDuration.String(),
+			}).Warn("LLM circuit breaker opened")
+		}
+
+		// Another attempt would wait out the client timeout again
+		if attempt >= c.config.MaxAttempts || isTimeout(err) {
+			if attempt > 1 {
+				err = &exhaustedError{attempts: attempt, err: err}
+			}
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		c.logger.WithFields(map[string]interface{}{
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		}).Warn("LLM request failed, retrying")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (c *ResilientClient) backoff(attempt int) time.Duration {
+	delay := c.config.InitialBackoff << (attempt - 1)
+	if delay <= 0 || delay > c.config.MaxBackoff {
+		delay = c.config.MaxBackoff
+	}
+	// Up to 20% jitter keeps queued retries from hitting the server together
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay - jitter
+}
+
+// isRetryable reports whether an attempt failed with a server error, rate
+// limiting or a connection problem. Timeouts of the HTTP client are not
+// retried.
+func isRetryable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode >= 500 || status.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return !netErr.Timeout()
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isTimeout reports whether an attempt failed because the HTTP client gave up
+// waiting for the server
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// Circuit breaker states
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// circuitBreaker opens after consecutive failures and lets a single trial
+// request through once it has been open long enough
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	openFor   time.Duration
+	now       func() time.Time
+
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+// isOpen reports whether requests are currently rejected
+func (b *circuitBreaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == circuitOpen && b.now().Sub(b.openedAt) < b.openFor
+}
+
+// allow reports whether a request may be sent, admitting the trial request
+// of an open breaker whose wait has elapsed
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.openFor {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// Only the trial request is sent
+		return false
+	default:
+		return true
+	}
+}
+
+// success closes the breaker
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = circuitClosed
+	b.failures = 0
+}
+
+// failure counts a failed attempt and reports whether it opened the breaker
+func (b *circuitBreaker) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == circuitHalfOpen || (b.state != circuitOpen && b.failures >= b.threshold) {
+		b.state = circuitOpen
+		b.openedAt = b.now()
+		return true
+	}
+	return false
+}
+
+// abandon ends a request without an outcome; an abandoned trial request lets
+// the next request try again
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == circuitHalfOpen {
+		b.state = circuitOpen
+	}
+}
+
+// requestQueue admits a bounded number of requests at once and hands freed
+// slots to waiting requests by priority, then arrival
+type requestQueue struct {
+	mu        sync.Mutex
+	limit     int
+	maxQueued int
+	active    int
+	waiting   waiterHeap
+	sequence  uint64
+}
+
+type waiter struct {
+	priority int
+	sequence uint64
+	ready    chan struct{}
+	index    int
+}
+
+func newRequestQueue(limit, maxQueued int) *requestQueue {
+	return &requestQueue{limit: limit, maxQueued: maxQueued}
+}
+
+// acquire waits for a slot
+func (q *requestQueue) acquire(ctx context.Context, priority int) error {
+	q.mu.Lock()
+	if q.active < q.limit && len(q.waiting) == 0 {
+		q.active++
+		q.mu.Unlock()
+		return nil
+	}
+	if len(q.waiting) >= q.maxQueued {
+		q.mu.Unlock()
+		return ErrQueueFull
+	}
+	w := &waiter{priority: priority, sequence: q.sequence, ready: make(chan struct{})}
+	q.sequence++
+	heap.Push(&q.waiting, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&q.waiting, w.index)
+			q.mu.Unlock()
+			return ctx.Err()
+		}
+		q.mu.Unlock()
+		// The slot was handed over while giving up; pass it on
+		q.release()
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it to the first waiting request
+func (q *requestQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) > 0 {
+		w := heap.Pop(&q.waiting).(*waiter)
+		close(w.ready)
+		return
+	}
+	q.active--
+}
+
+// waiterHeap orders waiting requests by descending priority, then arrival
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int { return len(h) }
+
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].sequence < h[j].sequence
+}
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*h = old[:len(old)-1]
+	return w
+}
@@ -0,0 +1,245 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetries keeps test backoffs short
+var fastRetries = ResilienceConfig{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestResilientClientRetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"model": "llama3", "response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	client := NewResilientClient(NewOllamaClientWithModel(server.URL, "llama3", log), fastRetries, log)
+
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestResilientClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	client := NewResilientClient(NewOllamaClientWithModel(server.URL, "llama3", log), fastRetries, log)
+
+	_, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	var status *StatusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusBadRequest, status.StatusCode)
+	assert.EqualError(t, err, "Ollama returned status 400")
+	assert.True(t, IsRejected(err))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestResilientClientRetriesRateLimits(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"model": "llama3", "response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	client := NewResilientClient(NewOllamaClientWithModel(server.URL, "llama3", log), fastRetries, log)
+
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestResilientClientDoesNotRetryClientTimeouts(t *testing.T) {
+	var calls int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	log := logger.NewLogrusLogger("error")
+	ollama := NewOllamaClientWithModel(server.URL, "llama3", log)
+	ollama.SetTimeout(20 * time.Millisecond)
+	client := NewResilientClient(ollama, fastRetries, log)
+
+	_, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.Error(t, err)
+	assert.True(t, isTimeout(err))
+	assert.NotErrorIs(t, err, ErrRetriesExhausted)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestResilientClientCircuitBreaker(t *testing.T) {
+	var calls int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"model": "llama3", "response": "ok", "done": true}`))
+	}))
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	config := fastRetries
+	config.MaxAttempts = 2
+	config.FailureThreshold = 3
+	config.OpenDuration = 50 * time.Millisecond
+	client := NewResilientClient(NewOllamaClientWithModel(server.URL, "llama3", log), config, log)
+	ctx := context.Background()
+
+	_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorContains(t, err, "LLM request failed after 2 attempts: Ollama returned status 500")
+	assert.ErrorIs(t, err, ErrRetriesExhausted)
+	assert.False(t, IsRejected(err))
+	_, err = client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorIs(t, err, ErrCircuitOpen, "the third failed attempt opens the breaker")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	_, err = client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "open breakers fail fast")
+
+	// A failed trial request opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	_, err = client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// A successful one closes it
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	resp, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+	_, err = client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+}
+
+// blockingClient holds every request until it is released
+type blockingClient struct {
+	recordingClient
+	mu      sync.Mutex
+	started chan string
+	release chan struct{}
+	order   []string
+}
+
+func (c *blockingClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.mu.Lock()
+	c.order = append(c.order, request.Prompt)
+	c.mu.Unlock()
+	c.started <- request.Prompt
+	<-c.release
+	return &interfaces.LLMResponse{Response: request.Prompt, Done: true}, nil
+}
+
+func TestResilientClientQueuesByPriority(t *testing.T) {
+	base := &blockingClient{started: make(chan string, 10), release: make(chan struct{})}
+	config := fastRetries
+	config.MaxConcurrent = 1
+	config.MaxQueued = 3
+	client := NewResilientClient(base, config, logger.NewLogrusLogger("error"))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	generate := func(ctx context.Context, prompt string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: prompt})
+			assert.NoError(t, err)
+		}()
+	}
+
+	generate(ctx, "first")
+	assert.Equal(t, "first", <-base.started)
+
+	generate(WithPriority(ctx, PriorityLow), "low")
+	waitForQueued(t, client, 1)
+	generate(ctx, "normal")
+	waitForQueued(t, client, 2)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := client.Generate(cancelled, interfaces.LLMRequest{Prompt: "cancelled"})
+		assert.ErrorIs(t, err, context.Canceled)
+	}()
+	waitForQueued(t, client, 3)
+
+	_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "overflow"})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	// A request giving up leaves the queue
+	cancel()
+	waitForQueued(t, client, 2)
+	generate(WithPriority(ctx, PriorityHigh), "high")
+	waitForQueued(t, client, 3)
+
+	close(base.release)
+	wg.Wait()
+	assert.Equal(t, []string{"first", "high", "normal", "low"}, base.order)
+}
+
+func waitForQueued(t *testing.T, client *ResilientClient, queued int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		client.queue.mu.Lock()
+		defer client.queue.mu.Unlock()
+		return len(client.queue.waiting) == queued
+	}, time.Second, time.Millisecond)
+}
+
+func TestResilientClientStopsRetryingOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	config := ResilienceConfig{InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	client := NewResilientClient(NewOllamaClientWithModel(server.URL, "llama3", log), config, log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// Stages of the framework that call the LLM, set as LLMRequest.Stage
+const (
+	StagePlanning   = "planning"
+	StageReplanning = "replanning"
+	StageAnalysis   = "analysis"
+	// StageSummarization is the summarize operation of analysis tasks
+	StageSummarization = "summarization"
+	// StageElementSelection chooses the next action and the page elements it
+	// targets when goals run iteratively
+	StageElementSelection = "element_selection"
+	// StageEmbedding computes vector embeddings, which need an embedding model
+	StageEmbedding = "embedding"
+)
+
+// Stages lists every stage a model route can be configured for
+var Stages = []string{StagePlanning, StageReplanning, StageAnalysis, StageSummarization, StageElementSelection, StageEmbedding}
+
+// stageFallbacks names the stage whose route applies when a stage has none
+var stageFallbacks = map[string]string{
+	StageReplanning:    StagePlanning,
+	StageSummarization: StageAnalysis,
+}
+
+// ModelRoute selects the model and generation options used for a stage.
+// Options override those set by the caller; an empty model keeps the
+// client's default model.
+type ModelRoute struct {
+	Model   string                 `json:"model,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// UnmarshalJSON accepts either a route object or a plain model name
+func (r *ModelRoute) UnmarshalJSON(data []byte) error {
+	var model string
+	if err := json.Unmarshal(data, &model); err == nil {
+		*r = ModelRoute{Model: model}
+		return nil
+	}
+
+	type route ModelRoute
+	var decoded route
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = ModelRoute(decoded)
+	return nil
+}
+
+// ParseModelRoutes decodes routes given as a JSON object keyed by stage, such
+// as {"planning": "qwen2.5:14b", "analysis": {"model": "llama3.2", "options": {"temperature": 0}}}
+func ParseModelRoutes(spec string) (map[string]ModelRoute, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var routes map[string]ModelRoute
+	if err := json.Unmarshal([]byte(spec), &routes); err != nil {
+		return nil, fmt.Errorf("invalid model routes: %w", err)
+	}
+	if err := ValidateModelRoutes(routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// ValidateModelRoutes rejects routes for unknown stages
+func ValidateModelRoutes(routes map[string]ModelRoute) error {
+	var unknown []string
+	for stage := range routes {
+		if !isStage(stage) {
+			unknown = append(unknown, stage)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("invalid model routes: unknown stage(s) %s; use one of: %s",
+			strings.Join(unknown, ", "), strings.Join(Stages, ", "))
+	}
+	return nil
+}
+
+func isStage(stage string) bool {
+	for _, known := range Stages {
+		if stage == known {
+			return true
+		}
+	}
+	return false
+}
+
+// RoutedClient sends every request to the model configured for its stage.
+// Requests of stages without a route, directly or through their fallback
+// stage, are passed through unchanged.
+type RoutedClient struct {
+	client interfaces.LLMClient
+	routes map[string]ModelRoute
+	logger interfaces.Logger
+}
+
+// NewRoutedClient wraps client with the given stage routes
+func NewRoutedClient(client interfaces.LLMClient, routes map[string]ModelRoute, logger interfaces.Logger) *RoutedClient {
+	return &RoutedClient{
+		client: client,
+		routes: routes,
+		logger: logger,
+	}
+}
+
+// Generate applies the stage's route to the request and forwards it
+func (c *RoutedClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	return c.client.Generate(ctx, c.Route(request))
+}
+
+// GenerateStream applies the stage's route to the request and streams the
+// response of the wrapped client
+func (c *RoutedClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	return Stream(ctx, c.client, c.Route(request))
+}
+
+// IsHealthy reports whether the wrapped client is healthy
+func (c *RoutedClient) IsHealthy(ctx context.Context) bool {
+	return c.client.IsHealthy(ctx)
+}
+
+// Chat applies the stage's route to the request and forwards it
+func (c *RoutedClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	request.Model, request.Options = c.apply(request.Stage, request.Model, request.Options)
+	return c.client.Chat(ctx, request)
+}
+
+// Embed applies the stage's route to the request and forwards it
+func (c *RoutedClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	request.Model, request.Options = c.apply(request.Stage, request.Model, request.Options)
+	return c.client.Embed(ctx, request)
+}
+
+// Route returns the request with the model and options of its stage's route
+func (c *RoutedClient) Route(request interfaces.LLMRequest) interfaces.LLMRequest {
+	request.Model, request.Options = c.apply(request.Stage, request.Model, request.Options)
+	return request
+}
+
+// apply returns the model and options to use for a request of the stage
+func (c *RoutedClient) apply(stage, model string, options map[string]interface{}) (string, map[string]interface{}) {
+	route, ok := c.lookup(stage)
+	if !ok {
+		return model, options
+	}
+
+	if route.Model != "" {
+		model = route.Model
+	}
+	if len(route.Options) > 0 {
+		merged := make(map[string]interface{}, len(options)+len(route.Options))
+		for key, value := range options {
+			merged[key] = value
+		}
+		for key, value := range route.Options {
+			merged[key] = value
+		}
+		options = merged
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"stage": stage,
+		"model": model,
+	}).Debug("Routed LLM request")
+
+	return model, options
+}
+
+// lookup finds the route of a stage, following stage fallbacks
+func (c *RoutedClient) lookup(stage string) (ModelRoute, bool) {
+	for stage != "" {
+		if route, ok := c.routes[stage]; ok {
+			return route, true
+		}
+		stage = stageFallbacks[stage]
+	}
+	return ModelRoute{}, false
+}
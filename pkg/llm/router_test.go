@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingClient records the requests it receives
+type recordingClient struct {
+	requests      []interfaces.LLMRequest
+	chatRequests  []interfaces.ChatRequest
+	embedRequests []interfaces.EmbeddingRequest
+}
+
+func (c *recordingClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.requests = append(c.requests, request)
+	return &interfaces.LLMResponse{Model: request.Model, Response: "ok", Done: true}, nil
+}
+
+func (c *recordingClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	c.chatRequests = append(c.chatRequests, request)
+	return &interfaces.ChatResponse{
+		Model:   request.Model,
+		Message: interfaces.ChatMessage{Role: interfaces.ChatRoleAssistant, Content: "ok"},
+		Done:    true,
+	}, nil
+}
+
+func (c *recordingClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	c.embedRequests = append(c.embedRequests, request)
+	return &interfaces.EmbeddingResponse{Model: request.Model}, nil
+}
+
+func (c *recordingClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+func TestParseModelRoutes(t *testing.T) {
+	routes, err := ParseModelRoutes(`{
+		"planning": "qwen2.5:14b",
+		"analysis": {"model": "llama3.2", "options": {"temperature": 0}}
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ModelRoute{
+		StagePlanning: {Model: "qwen2.5:14b"},
+		StageAnalysis: {Model: "llama3.2", Options: map[string]interface{}{"temperature": float64(0)}},
+	}, routes)
+
+	routes, err = ParseModelRoutes("  ")
+	require.NoError(t, err)
+	assert.Nil(t, routes)
+
+	_, err = ParseModelRoutes(`{"planing": "llama3", "coding": "qwen"}`)
+	assert.EqualError(t, err, "invalid model routes: unknown stage(s) coding, planing; use one of: planning, replanning, analysis, summarization, element_selection, embedding")
+
+	_, err = ParseModelRoutes(`{"planning": 3}`)
+	assert.Error(t, err)
+}
+
+func TestRoutedClientAppliesStageRoutes(t *testing.T) {
+	base := &recordingClient{}
+	client := NewRoutedClient(base, map[string]ModelRoute{
+		StagePlanning: {Model: "qwen2.5:14b", Options: map[string]interface{}{"temperature": 0.1}},
+		StageAnalysis: {Options: map[string]interface{}{"num_ctx": 8192}},
+	}, logger.NewLogrusLogger("error"))
+
+	ctx := context.Background()
+	requests := []interfaces.LLMRequest{
+		{Stage: StagePlanning, Options: map[string]interface{}{"temperature": 0.7, "num_predict": 2000}},
+		{Stage: StageReplanning},
+		{Stage: StageSummarization, Model: "phi3"},
+		{Stage: StageElementSelection},
+		{},
+	}
+	for _, request := range requests {
+		_, err := client.Generate(ctx, request)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, base.requests, 5)
+	assert.Equal(t, "qwen2.5:14b", base.requests[0].Model)
+	assert.Equal(t, map[string]interface{}{"temperature": 0.1, "num_predict": 2000}, base.requests[0].Options)
+	assert.Equal(t, "qwen2.5:14b", base.requests[1].Model, "replanning falls back to the planning route")
+	assert.Equal(t, "phi3", base.requests[2].Model, "routes without a model keep the requested one")
+	assert.Equal(t, map[string]interface{}{"num_ctx": 8192}, base.requests[2].Options)
+	assert.Empty(t, base.requests[3].Model, "unrouted stages use the client default")
+	assert.Empty(t, base.requests[4].Model)
+
+	// The caller's options are not modified
+	assert.Equal(t, 0.7, requests[0].Options["temperature"])
+}
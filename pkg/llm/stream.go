@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// ChunkHandler receives the chunks of a streamed response as they arrive
+type ChunkHandler func(chunk interfaces.LLMChunk)
+
+type chunkHandlerKey struct{}
+
+// WithChunkHandler returns a context whose LLM calls made through Generate
+// are streamed, passing every chunk to handler
+func WithChunkHandler(ctx context.Context, handler ChunkHandler) context.Context {
+	return context.WithValue(ctx, chunkHandlerKey{}, handler)
+}
+
+// Generate sends a request through client. When ctx carries a chunk handler
+// (see WithChunkHandler) the response is streamed to it and then returned as
+// a whole, so callers can show output live without handling streams.
+func Generate(ctx context.Context, client interfaces.LLMClient, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	handler, _ := ctx.Value(chunkHandlerKey{}).(ChunkHandler)
+	if handler == nil {
+		return client.Generate(ctx, request)
+	}
+
+	chunks, err := Stream(ctx, client, request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := CollectStream(chunks, handler)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return resp, err
+}
+
+// Stream streams a response from client. Clients without streaming support
+// produce the whole response as a single final chunk.
+func Stream(ctx context.Context, client interfaces.LLMClient, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	if streaming, ok := client.(interfaces.StreamingLLMClient); ok {
+		return streaming.GenerateStream(ctx, request)
+	}
+
+	request.Stream = false
+	resp, err := client.Generate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk, 1)
+	chunks <- interfaces.LLMChunk{
+		Model:     resp.Model,
+		Response:  resp.Response,
+		Reasoning: resp.Reasoning,
+		Done:      true,
+		Context:   resp.Context,
+		LLMStats:  resp.LLMStats,
+	}
+	close(chunks)
+	return chunks, nil
+}
+
+// CollectStream reads a stream to its end and assembles the response,
+// passing every chunk to handler if one is given
+func CollectStream(chunks <-chan interfaces.LLMChunk, handler ChunkHandler) (*interfaces.LLMResponse, error) {
+	var text, reasoning strings.Builder
+	resp := &interfaces.LLMResponse{}
+
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		if handler != nil {
+			handler(chunk)
+		}
+
+		text.WriteString(chunk.Response)
+		reasoning.WriteString(chunk.Reasoning)
+		if chunk.Model != "" {
+			resp.Model = chunk.Model
+		}
+		if chunk.Done {
+			resp.Done = true
+			resp.Context = chunk.Context
+			resp.LLMStats = chunk.LLMStats
+		}
+	}
+
+	if !resp.Done {
+		return nil, fmt.Errorf("stream ended before the response was complete")
+	}
+
+	resp.Response = text.String()
+	resp.Reasoning = strings.TrimSpace(reasoning.String())
+
+	// Streams leave reasoning ended by a dangling </think> in the answer
+	resp.Response, resp.Reasoning = separateReasoning(resp.Response, resp.Reasoning)
+	return resp, nil
+}
@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamingServer serves the given NDJSON lines from /api/generate and
+// records the decoded request
+func newStreamingServer(t *testing.T, lines []string, received *map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if received != nil {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(received))
+		}
+		flusher := w.(http.Flusher)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			flusher.Flush()
+		}
+	}))
+}
+
+func TestOllamaClientStreamsChunks(t *testing.T) {
+	var received map[string]interface{}
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "Hel", "done": false}`,
+		`{"model": "llama3", "response": "lo", "done": false}`,
+		`{"model": "llama3", "response": "", "done": true, "eval_count": 2, "prompt_eval_count": 5, "total_duration": 1500000000}`,
+	}, &received)
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	chunks, err := client.GenerateStream(context.Background(), interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+
+	var collected []interfaces.LLMChunk
+	for chunk := range chunks {
+		collected = append(collected, chunk)
+	}
+
+	require.Len(t, collected, 3)
+	assert.Equal(t, "Hel", collected[0].Response)
+	assert.True(t, collected[2].Done)
+	assert.Equal(t, 2, collected[2].EvalCount)
+	assert.Equal(t, 5, collected[2].PromptEvalCount)
+	assert.Equal(t, 1500*time.Millisecond, collected[2].TotalDuration)
+	assert.Equal(t, true, received["stream"])
+	assert.Equal(t, "llama3", received["model"])
+}
+
+func TestOllamaClientGenerateCollectsStreamedResponse(t *testing.T) {
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "{\"a\":", "done": false}`,
+		`{"model": "llama3", "response": " 1}", "done": true, "eval_count": 4}`,
+	}, nil)
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	resp, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi", Stream: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, resp.Response)
+	assert.True(t, resp.Done)
+	assert.Equal(t, 4, resp.EvalCount)
+}
+
+func TestOllamaClientStreamReportsErrors(t *testing.T) {
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "partial", "done": false}`,
+		`{"error": "model ran out of memory"}`,
+	}, nil)
+	defer server.Close()
+
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	_, err := client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi", Stream: true})
+	assert.EqualError(t, err, "Ollama stream failed: model ran out of memory")
+
+	truncated := newStreamingServer(t, []string{`{"model": "llama3", "response": "partial", "done": false}`}, nil)
+	defer truncated.Close()
+
+	client = NewOllamaClientWithModel(truncated.URL, "llama3", logger.NewLogrusLogger("error"))
+	_, err = client.Generate(context.Background(), interfaces.LLMRequest{Prompt: "hi", Stream: true})
+	assert.EqualError(t, err, "Ollama stream ended before the final chunk")
+}
+
+func TestOllamaClientStreamStopsOnCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"model": "llama3", "response": "first", "done": false}`)
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error"))
+	chunks, err := client.GenerateStream(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+
+	first := <-chunks
+	assert.Equal(t, "first", first.Response)
+	cancel()
+
+	select {
+	case _, ok := <-chunks:
+		for ok {
+			_, ok = <-chunks
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not closed after cancellation")
+	}
+}
+
+func TestGenerateStreamsToContextHandler(t *testing.T) {
+	ctx := context.Background()
+	base := &recordingClient{}
+
+	// Without a handler the request is sent as is
+	resp, err := Generate(ctx, base, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+
+	// Clients without streaming support deliver a single final chunk
+	var chunks []interfaces.LLMChunk
+	ctx = WithChunkHandler(ctx, func(chunk interfaces.LLMChunk) {
+		chunks = append(chunks, chunk)
+	})
+	resp, err = Generate(ctx, base, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp.Response)
+	require.Len(t, chunks, 1)
+	assert.True(t, chunks[0].Done)
+
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "a", "done": false}`,
+		`{"model": "llama3", "response": "b", "done": true}`,
+	}, nil)
+	defer server.Close()
+
+	chunks = nil
+	routed := NewRoutedClient(NewOllamaClientWithModel(server.URL, "llama3", logger.NewLogrusLogger("error")), nil, logger.NewLogrusLogger("error"))
+	resp, err = Generate(ctx, routed, interfaces.LLMRequest{Prompt: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "ab", resp.Response)
+	assert.Len(t, chunks, 2)
+}
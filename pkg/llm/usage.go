@@ -0,0 +1,263 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// stageOther groups the usage of requests that name no stage
+const stageOther = "other"
+
+// ErrTokenBudgetExceeded is matched by the errors of requests refused because
+// their usage meter's token budget is spent
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// BudgetExceededError reports a request refused by an exhausted token budget
+type BudgetExceededError struct {
+	Budget int
+	Used   int
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("token budget of %d exceeded: %d tokens used", e.Budget, e.Used)
+}
+
+// Unwrap makes the error match ErrTokenBudgetExceeded
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrTokenBudgetExceeded
+}
+
+// UsageMeter sums the LLM usage of a unit of work, such as a plan, by stage
+// and task, and enforces an optional token budget. The budget is checked
+// before each request, so the request crossing it completes and later ones
+// are refused.
+type UsageMeter struct {
+	mu    sync.Mutex
+	usage interfaces.PlanUsage
+}
+
+// NewUsageMeter creates a meter; a budget of 0 is unlimited
+func NewUsageMeter(tokenBudget int) *UsageMeter {
+	return &UsageMeter{usage: interfaces.PlanUsage{TokenBudget: tokenBudget}}
+}
+
+// Check returns a *BudgetExceededError once the budget is spent
+func (m *UsageMeter) Check() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	budget := m.usage.TokenBudget
+	if budget > 0 && m.usage.TotalTokens >= budget {
+		return &BudgetExceededError{Budget: budget, Used: m.usage.TotalTokens}
+	}
+	return nil
+}
+
+// Record adds the statistics of a completed request
+func (m *UsageMeter) Record(stage, taskID string, stats interfaces.LLMStats) {
+	if stage == "" {
+		stage = stageOther
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addUsage(&m.usage.LLMUsage, stats)
+
+	if m.usage.ByStage == nil {
+		m.usage.ByStage = make(map[string]interfaces.LLMUsage)
+	}
+	byStage := m.usage.ByStage[stage]
+	addUsage(&byStage, stats)
+	m.usage.ByStage[stage] = byStage
+
+	if taskID != "" {
+		if m.usage.ByTask == nil {
+			m.usage.ByTask = make(map[string]interfaces.LLMUsage)
+		}
+		byTask := m.usage.ByTask[taskID]
+		addUsage(&byTask, stats)
+		m.usage.ByTask[taskID] = byTask
+	}
+}
+
+// Snapshot returns a copy of the usage recorded so far
+func (m *UsageMeter) Snapshot() *interfaces.PlanUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.usage
+	snapshot.ByStage = copyUsageMap(m.usage.ByStage)
+	snapshot.ByTask = copyUsageMap(m.usage.ByTask)
+	return &snapshot
+}
+
+func addUsage(usage *interfaces.LLMUsage, stats interfaces.LLMStats) {
+	usage.Calls++
+	usage.PromptTokens += stats.PromptEvalCount
+	usage.CompletionTokens += stats.EvalCount
+	usage.TotalTokens += stats.PromptEvalCount + stats.EvalCount
+	usage.Duration += stats.TotalDuration
+}
+
+func copyUsageMap(usage map[string]interfaces.LLMUsage) map[string]interfaces.LLMUsage {
+	if usage == nil {
+		return nil
+	}
+	copied := make(map[string]interfaces.LLMUsage, len(usage))
+	for key, value := range usage {
+		copied[key] = value
+	}
+	return copied
+}
+
+type usageMeterKey struct{}
+type usageTaskKey struct{}
+
+// WithUsageMeter returns a context whose LLM requests made through a
+// MeteredClient are recorded by meter and bounded by its budget
+func WithUsageMeter(ctx context.Context, meter *UsageMeter) context.Context {
+	return context.WithValue(ctx, usageMeterKey{}, meter)
+}
+
+// UsageMeterFrom returns the meter of ctx, or nil
+func UsageMeterFrom(ctx context.Context) *UsageMeter {
+	meter, _ := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	return meter
+}
+
+// WithUsageTask returns a context whose LLM usage is attributed to a task
+func WithUsageTask(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, usageTaskKey{}, taskID)
+}
+
+// taskIDFrom returns the task set by WithUsageTask, or ""
+func taskIDFrom(ctx context.Context) string {
+	taskID, _ := ctx.Value(usageTaskKey{}).(string)
+	return taskID
+}
+
+// MeteredClient records the usage of every request in a total meter, by
+// stage only, and in the meter of the request's context, refusing requests
+// whose context meter has spent its budget
+type MeteredClient struct {
+	client interfaces.LLMClient
+	total  *UsageMeter
+	logger interfaces.Logger
+}
+
+// NewMeteredClient wraps client
+func NewMeteredClient(client interfaces.LLMClient, logger interfaces.Logger) *MeteredClient {
+	return &MeteredClient{
+		client: client,
+		total:  NewUsageMeter(0),
+		logger: logger,
+	}
+}
+
+// Usage returns the usage of all requests sent through the client
+func (c *MeteredClient) Usage() *interfaces.PlanUsage {
+	return c.total.Snapshot()
+}
+
+// Generate forwards the request within the budget and records its usage
+func (c *MeteredClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	if err := c.check(ctx, request.Stage); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Generate(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.record(ctx, request.Stage, resp.LLMStats)
+	return resp, nil
+}
+
+// GenerateStream streams the response within the budget and records its
+// usage with the final chunk
+func (c *MeteredClient) GenerateStream(ctx context.Context, request interfaces.LLMRequest) (<-chan interfaces.LLMChunk, error) {
+	if err := c.check(ctx, request.Stage); err != nil {
+		return nil, err
+	}
+	upstream, err := Stream(ctx, c.client, request)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan interfaces.LLMChunk)
+	go func() {
+		defer close(chunks)
+		for chunk := range upstream {
+			if chunk.Done {
+				c.record(ctx, request.Stage, chunk.LLMStats)
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// Chat forwards the request within the budget and records its usage
+func (c *MeteredClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	if err := c.check(ctx, request.Stage); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Chat(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.record(ctx, request.Stage, resp.LLMStats)
+	return resp, nil
+}
+
+// Embed forwards the request within the budget and records its usage
+func (c *MeteredClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	if err := c.check(ctx, request.Stage); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Embed(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.record(ctx, request.Stage, resp.LLMStats)
+	return resp, nil
+}
+
+// IsHealthy reports whether the wrapped client is healthy
+func (c *MeteredClient) IsHealthy(ctx context.Context) bool {
+	return c.client.IsHealthy(ctx)
+}
+
+// check refuses a request whose context meter has spent its budget
+func (c *MeteredClient) check(ctx context.Context, stage string) error {
+	meter := UsageMeterFrom(ctx)
+	if meter == nil {
+		return nil
+	}
+	if err := meter.Check(); err != nil {
+		c.logger.WithFields(map[string]interface{}{
+			"stage": stage,
+			"error": err.Error(),
+		}).Warn("LLM request refused")
+		return err
+	}
+	return nil
+}
+
+// record adds the statistics of a completed request to the meters
+func (c *MeteredClient) record(ctx context.Context, stage string, stats interfaces.LLMStats) {
+	taskID := taskIDFrom(ctx)
+	c.total.Record(stage, "", stats)
+	if meter := UsageMeterFrom(ctx); meter != nil {
+		meter.Record(stage, taskID, stats)
+	}
+}
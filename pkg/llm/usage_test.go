@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statsClient reports the same statistics for every response
+type statsClient struct {
+	recordingClient
+	stats interfaces.LLMStats
+}
+
+func (c *statsClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.requests = append(c.requests, request)
+	return &interfaces.LLMResponse{Response: "ok", Done: true, LLMStats: c.stats}, nil
+}
+
+func (c *statsClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	return &interfaces.EmbeddingResponse{LLMStats: interfaces.LLMStats{PromptEvalCount: 3}}, nil
+}
+
+func TestMeteredClientRecordsUsageByStageAndTask(t *testing.T) {
+	base := &statsClient{stats: interfaces.LLMStats{PromptEvalCount: 100, EvalCount: 20, TotalDuration: time.Second}}
+	client := NewMeteredClient(base, logger.NewLogrusLogger("error"))
+
+	meter := NewUsageMeter(0)
+	ctx := WithUsageMeter(context.Background(), meter)
+
+	_, err := client.Generate(ctx, interfaces.LLMRequest{Stage: StagePlanning})
+	require.NoError(t, err)
+	_, err = client.Generate(WithUsageTask(ctx, "task-1"), interfaces.LLMRequest{Stage: StageAnalysis})
+	require.NoError(t, err)
+	_, err = client.Embed(WithUsageTask(ctx, "task-1"), interfaces.EmbeddingRequest{Input: []string{"a"}})
+	require.NoError(t, err)
+
+	// Requests without a meter only count towards the total
+	_, err = client.Generate(context.Background(), interfaces.LLMRequest{Stage: StagePlanning})
+	require.NoError(t, err)
+
+	usage := meter.Snapshot()
+	assert.Equal(t, interfaces.LLMUsage{Calls: 3, PromptTokens: 203, CompletionTokens: 40, TotalTokens: 243, Duration: 2 * time.Second}, usage.LLMUsage)
+	assert.Equal(t, 120, usage.ByStage[StagePlanning].TotalTokens)
+	assert.Equal(t, 3, usage.ByStage["other"].TotalTokens)
+	assert.Equal(t, interfaces.LLMUsage{Calls: 2, PromptTokens: 103, CompletionTokens: 20, TotalTokens: 123, Duration: time.Second}, usage.ByTask["task-1"])
+
+	total := client.Usage()
+	assert.Equal(t, 4, total.Calls)
+	assert.Equal(t, 240, total.ByStage[StagePlanning].TotalTokens)
+	assert.Empty(t, total.ByTask)
+
+	// Snapshots are copies
+	usage.ByStage[StagePlanning] = interfaces.LLMUsage{}
+	assert.Equal(t, 120, meter.Snapshot().ByStage[StagePlanning].TotalTokens)
+}
+
+func TestMeteredClientEnforcesTokenBudget(t *testing.T) {
+	base := &statsClient{stats: interfaces.LLMStats{PromptEvalCount: 30, EvalCount: 10}}
+	client := NewMeteredClient(base, logger.NewLogrusLogger("error"))
+	ctx := WithUsageMeter(context.Background(), NewUsageMeter(60))
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+		require.NoError(t, err, "requests run while the budget is not spent")
+	}
+
+	_, err := client.Generate(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.EqualError(t, err, "token budget of 60 exceeded: 80 tokens used")
+	assert.ErrorIs(t, err, ErrTokenBudgetExceeded)
+	_, err = client.GenerateStream(ctx, interfaces.LLMRequest{Prompt: "hi"})
+	assert.ErrorIs(t, err, ErrTokenBudgetExceeded)
+	assert.Len(t, base.requests, 2)
+}
+
+func TestMeteredClientRecordsStreamedUsage(t *testing.T) {
+	server := newStreamingServer(t, []string{
+		`{"model": "llama3", "response": "a", "done": false}`,
+		`{"model": "llama3", "response": "b", "done": true, "prompt_eval_count": 7, "eval_count": 2}`,
+	}, nil)
+	defer server.Close()
+
+	log := logger.NewLogrusLogger("error")
+	client := NewMeteredClient(NewOllamaClientWithModel(server.URL, "llama3", log), log)
+	meter := NewUsageMeter(0)
+	ctx := WithChunkHandler(WithUsageMeter(context.Background(), meter), func(interfaces.LLMChunk) {})
+
+	resp, err := Generate(ctx, client, interfaces.LLMRequest{Prompt: "hi", Stage: StagePlanning})
+	require.NoError(t, err)
+	assert.Equal(t, "ab", resp.Response)
+	assert.Equal(t, 9, meter.Snapshot().TotalTokens)
+}
@@ -0,0 +1,223 @@
+// Package planfile loads hand-authored plans from YAML or JSON files so known
+// workflows can run without asking the LLM for a plan. A plan file mirrors
+// interfaces.Plan: tasks are named, depend on each other by name and may pass
+// results along with parameter templates such as {{ tasks.fetch.result }}.
+//
+//	goal: Collect the links of example.com
+//	tasks:
+//	  - name: fetch
+//	    type: browser
+//	    description: Open the page
+//	    parameters:
+//	      action: navigate
+//	      url: https://example.com
+//	  - name: links
+//	    type: browser
+//	    description: Extract the links
+//	    parameters:
+//	      action: extract
+//	      extract_type: links
+//	    dependencies: [fetch]
+package planfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/planner"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// File is a hand-authored plan
+type File struct {
+	Goal  string `yaml:"goal" json:"goal"`
+	Tasks []Task `yaml:"tasks" json:"tasks"`
+}
+
+// Task is a task of a plan file. Dependencies name other tasks of the file.
+type Task struct {
+	Name         string                 `yaml:"name" json:"name"`
+	Type         string                 `yaml:"type" json:"type"`
+	Description  string                 `yaml:"description" json:"description"`
+	Parameters   map[string]interface{} `yaml:"parameters" json:"parameters"`
+	Dependencies []string               `yaml:"dependencies" json:"dependencies"`
+}
+
+// Load reads a plan file from disk
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	file, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// Parse decodes a plan file. JSON is accepted as well since it is valid
+// YAML. Unknown fields are rejected to catch misspelled keys.
+func Parse(data []byte) (*File, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file File
+	if err := decoder.Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("plan file is empty")
+		}
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+
+	// Normalize parameters to the types produced by JSON decoding, which is
+	// what handlers and parameter schemas expect
+	for i := range file.Tasks {
+		if file.Tasks[i].Parameters == nil {
+			continue
+		}
+		encoded, err := json.Marshal(file.Tasks[i].Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("task %d: parameters are not JSON compatible: %w", i+1, err)
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal(encoded, &params); err != nil {
+			return nil, fmt.Errorf("task %d: parameters are not JSON compatible: %w", i+1, err)
+		}
+		file.Tasks[i].Parameters = params
+	}
+
+	return &file, nil
+}
+
+// Plan turns the file into an executable plan, assigning task IDs and
+// resolving dependencies. The plan is validated like an LLM generated one;
+// when handlers is given, task types and parameters are also checked against
+// the registered handlers. All problems are reported together as a
+// *planner.PlanValidationError.
+func (f *File) Plan(handlers interfaces.HandlerCatalog) (*interfaces.Plan, error) {
+	var problems []string
+	if len(f.Tasks) == 0 {
+		problems = append(problems, "plan has no tasks")
+	}
+
+	now := time.Now()
+	tasks := make([]interfaces.Task, len(f.Tasks))
+	byName := make(map[string]int, len(f.Tasks))
+	var names []string
+
+	for i, fileTask := range f.Tasks {
+		name := strings.TrimSpace(fileTask.Name)
+		if name == "" {
+			problems = append(problems, fmt.Sprintf("task %d: name is required", i+1))
+		} else if first, exists := byName[name]; exists {
+			problems = append(problems, fmt.Sprintf("tasks %d and %d are both named %q; task names must be unique", first+1, i+1, name))
+		} else {
+			byName[name] = i
+			names = append(names, name)
+		}
+		if strings.TrimSpace(fileTask.Type) == "" {
+			problems = append(problems, fmt.Sprintf("%s: type is required", planner.DescribeTask(i, name)))
+		}
+
+		description := fileTask.Description
+		if description == "" {
+			description = name
+		}
+		params := fileTask.Parameters
+		if params == nil {
+			params = map[string]interface{}{}
+		}
+
+		tasks[i] = interfaces.Task{
+			ID:          uuid.New().String(),
+			Name:        name,
+			Type:        fileTask.Type,
+			Description: description,
+			Parameters:  params,
+			Status:      interfaces.TaskStatusPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+
+	for i, fileTask := range f.Tasks {
+		for _, dep := range fileTask.Dependencies {
+			target, ok := byName[strings.TrimSpace(dep)]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: dependency %q does not match any task; reference one of the task names: %s",
+					planner.DescribeTask(i, tasks[i].Name), dep, strings.Join(names, ", ")))
+				continue
+			}
+			tasks[i].Dependencies = append(tasks[i].Dependencies, tasks[target].ID)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &planner.PlanValidationError{Problems: problems}
+	}
+
+	var descriptions []interfaces.HandlerDescription
+	if handlers != nil {
+		descriptions = handlers.DescribeHandlers()
+	}
+	if err := Validate(tasks, descriptions); err != nil {
+		return nil, err
+	}
+
+	return &interfaces.Plan{
+		ID:        uuid.New().String(),
+		Goal:      f.Goal,
+		Tasks:     tasks,
+		Status:    interfaces.TaskStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Validate checks that tasks form an executable plan for the given handlers:
+// the dependency graph is sound, parameter expressions only reference
+// dependencies, every task type is registered and the parameters match the
+// handler's schema. Empty descriptions skip the handler checks.
+func Validate(tasks []interfaces.Task, descriptions []interfaces.HandlerDescription) error {
+	taskTypes := make([]string, len(descriptions))
+	byType := make(map[string]interfaces.HandlerDescription, len(descriptions))
+	for i, description := range descriptions {
+		taskTypes[i] = description.TaskType
+		byType[description.TaskType] = description
+	}
+
+	var problems []string
+	if err := planner.ValidateTasks(tasks, taskTypes); err != nil {
+		var validationErr *planner.PlanValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		problems = append(problems, validationErr.Problems...)
+	}
+
+	for i, task := range tasks {
+		description, ok := byType[task.Type]
+		if !ok {
+			continue
+		}
+		if err := executor.ValidatePlannedParameters(description, task.Parameters); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid parameters: %v", planner.DescribeTask(i, task.Name), err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &planner.PlanValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package planfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/eventbus"
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/ai-agent-framework/pkg/planner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCatalog returns an executor with the api handler registered
+func newCatalog() interfaces.HandlerCatalog {
+	log := logger.NewLogrusLogger("error")
+	store := memory.NewInMemoryStore(log)
+	taskExecutor := executor.NewTaskExecutor(store, eventbus.NewInMemoryEventBus(log), log)
+	taskExecutor.RegisterHandler("api", executor.NewAPITaskHandler(log))
+	return taskExecutor
+}
+
+const yamlPlan = `
+goal: Fetch a user and their orders
+tasks:
+  - name: user
+    type: api
+    description: Fetch the user
+    parameters:
+      url: https://api.example.com/users/1
+      retry:
+        max_attempts: 3
+  - name: orders
+    type: api
+    parameters:
+      url: "https://api.example.com/orders?user={{ tasks.user.result.body.id }}"
+    dependencies: [user]
+`
+
+func TestLoadYAMLPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yamlPlan), 0o644))
+
+	file, err := Load(path)
+	require.NoError(t, err)
+
+	plan, err := file.Plan(newCatalog())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fetch a user and their orders", plan.Goal)
+	assert.Equal(t, interfaces.TaskStatusPending, plan.Status)
+	require.Len(t, plan.Tasks, 2)
+
+	user, orders := plan.Tasks[0], plan.Tasks[1]
+	assert.Equal(t, "user", user.Name)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, map[string]interface{}{"max_attempts": float64(3)}, user.Parameters["retry"], "parameters are normalized like JSON")
+	assert.Equal(t, "orders", orders.Description, "the name is the default description")
+	assert.Equal(t, []string{user.ID}, orders.Dependencies)
+}
+
+func TestParseJSONPlan(t *testing.T) {
+	file, err := Parse([]byte(`{"goal": "ping", "tasks": [{"name": "ping", "type": "api", "parameters": {"url": "https://example.com"}}]}`))
+	require.NoError(t, err)
+
+	plan, err := file.Plan(nil)
+	require.NoError(t, err)
+	require.Len(t, plan.Tasks, 1)
+	assert.Equal(t, "https://example.com", plan.Tasks[0].Parameters["url"])
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	_, err := Parse([]byte("tasks:\n  - name: a\n    type: api\n    depends_on: [b]\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "depends_on")
+
+	_, err = Parse(nil)
+	assert.EqualError(t, err, "plan file is empty")
+}
+
+func TestPlanReportsAllProblems(t *testing.T) {
+	file, err := Parse([]byte(`
+tasks:
+  - name: fetch
+    type: api
+    parameters:
+      endpoint: https://example.com
+  - name: fetch
+    type: api
+    parameters:
+      url: https://example.com
+  - name: parse
+    type: shell
+    dependencies: [fech]
+`))
+	require.NoError(t, err)
+
+	_, err = file.Plan(newCatalog())
+	var validationErr *planner.PlanValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a PlanValidationError, got %v", err)
+	assert.Equal(t, []string{
+		`tasks 1 and 2 are both named "fetch"; task names must be unique`,
+		`task 3 ("parse"): dependency "fech" does not match any task; reference one of the task names: fetch, parse`,
+	}, validationErr.Problems)
+
+	file.Tasks[1].Name = "fetch_again"
+	file.Tasks[2].Dependencies = []string{"fetch"}
+
+	_, err = file.Plan(newCatalog())
+	require.True(t, errors.As(err, &validationErr), "expected a PlanValidationError, got %v", err)
+	require.Len(t, validationErr.Problems, 2)
+	assert.Contains(t, validationErr.Problems[0], `task 3 ("parse"): unknown task type "shell"; use one of: api`)
+	assert.Contains(t, validationErr.Problems[1], `task 1 ("fetch"): invalid parameters: `)
+	assert.Contains(t, validationErr.Problems[1], "url: is required")
+}
+
+func TestPlanRejectsReferencesToNonDependencies(t *testing.T) {
+	file := &File{Tasks: []Task{
+		{Name: "user", Type: "api", Parameters: map[string]interface{}{"url": "https://api.example.com/users/1"}},
+		{Name: "orders", Type: "api", Parameters: map[string]interface{}{
+			"url":     "https://api.example.com/orders?user={{ tasks.user.result.body.id }}",
+			"headers": map[string]interface{}{"X-Trace": "{{ tasks.trace.result }}"},
+		}},
+	}}
+
+	_, err := file.Plan(newCatalog())
+	var validationErr *planner.PlanValidationError
+	require.True(t, errors.As(err, &validationErr), "expected a PlanValidationError, got %v", err)
+	assert.Equal(t, []string{
+		`task 2 ("orders"): parameter expression references "trace", which is not a dependency of this task; add it to dependencies`,
+		`task 2 ("orders"): parameter expression references "user", which is not a dependency of this task; add it to dependencies`,
+	}, validationErr.Problems)
+
+	file.Tasks[1].Parameters["headers"] = map[string]interface{}{}
+	file.Tasks[1].Dependencies = []string{"user"}
+	plan, err := file.Plan(newCatalog())
+	require.NoError(t, err)
+
+	// Expressions may also reference a dependency by its ID
+	plan.Tasks[1].Parameters["url"] = "{{ tasks." + plan.Tasks[0].ID + ".result }}"
+	assert.NoError(t, Validate(plan.Tasks, nil))
+}
+
+func TestPlanRejectsCycles(t *testing.T) {
+	file := &File{Tasks: []Task{
+		{Name: "a", Type: "api", Dependencies: []string{"b"}},
+		{Name: "b", Type: "api", Dependencies: []string{"a"}},
+	}}
+
+	_, err := file.Plan(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle a -> b -> a")
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
 )
 
 // TaskPlanner implements the Planner interface
@@ -16,17 +17,29 @@ type TaskPlanner struct {
 	llmClient interfaces.LLMClient
 	memory    interfaces.MemoryStore
 	logger    interfaces.Logger
+	handlers  interfaces.HandlerCatalog
+
+	// repairAttempts bounds how often an unusable plan is sent back for correction
+	repairAttempts int
 }
 
 // NewTaskPlanner creates a new task planner
 func NewTaskPlanner(llmClient interfaces.LLMClient, memory interfaces.MemoryStore, logger interfaces.Logger) *TaskPlanner {
 	return &TaskPlanner{
-		llmClient: llmClient,
-		memory:    memory,
-		logger:    logger,
+		llmClient:      llmClient,
+		memory:         memory,
+		logger:         logger,
+		repairAttempts: defaultRepairAttempts,
 	}
 }
 
+// SetHandlerCatalog sets the source of the task types and parameter schemas
+// offered to the LLM. It is read on every planning call so handlers registered
+// later are picked up.
+func (p *TaskPlanner) SetHandlerCatalog(handlers interfaces.HandlerCatalog) {
+	p.handlers = handlers
+}
+
 // CreatePlan breaks down a goal into executable tasks
 func (p *TaskPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.Plan, error) {
 	p.logger.WithField("goal", goal).Info("Creating plan")
@@ -34,25 +47,9 @@ func (p *TaskPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.
 	// Generate plan using LLM
 	prompt := p.buildPlanningPrompt(goal)
 	
-	llmReq := interfaces.LLMRequest{
-		Model:  "llama3",
-		Prompt: prompt,
-		Stream: false,
-		Options: map[string]interface{}{
-			"temperature": 0.7,
-			"max_tokens":  2000,
-		},
-	}
-
-	resp, err := p.llmClient.Generate(ctx, llmReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate plan: %w", err)
-	}
-
-	// Parse the LLM response into tasks
-	tasks, err := p.parsePlanResponse(resp.Response)
+	tasks, err := p.generateTasks(ctx, llm.StagePlanning, prompt, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse plan response: %w", err)
+		return nil, err
 	}
 
 	// Create plan object
@@ -61,6 +58,7 @@ func (p *TaskPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.
 		Goal:      goal,
 		Tasks:     tasks,
 		Status:    interfaces.TaskStatusPending,
+		Revision:  1,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -69,6 +67,7 @@ func (p *TaskPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.
 	if err := p.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
 		p.logger.WithField("error", err).Warn("Failed to store plan in memory")
 	}
+	p.recordRevision(ctx, plan, nil, "", nil)
 
 	p.logger.WithFields(map[string]interface{}{
 		"plan_id":    plan.ID,
@@ -78,7 +77,9 @@ func (p *TaskPlanner) CreatePlan(ctx context.Context, goal string) (*interfaces.
 	return plan, nil
 }
 
-// UpdatePlan modifies an existing plan based on feedback
+// UpdatePlan revises an existing plan based on feedback. The previous tasks
+// are kept in the plan's revision history, and completed tasks the revision
+// leaves unchanged keep their state so they do not run again.
 func (p *TaskPlanner) UpdatePlan(ctx context.Context, planID string, feedback string) (*interfaces.Plan, error) {
 	p.logger.WithFields(map[string]interface{}{
 		"plan_id":  planID,
@@ -91,40 +92,43 @@ func (p *TaskPlanner) UpdatePlan(ctx context.Context, planID string, feedback st
 		return nil, fmt.Errorf("failed to retrieve plan: %w", err)
 	}
 
+	history := p.loadHistory(ctx, plan)
+
 	// Generate updated plan using LLM
 	prompt := p.buildUpdatePrompt(plan, feedback)
 	
-	llmReq := interfaces.LLMRequest{
-		Model:  "llama3",
-		Prompt: prompt,
-		Stream: false,
-		Options: map[string]interface{}{
-			"temperature": 0.7,
-			"max_tokens":  2000,
-		},
-	}
-
-	resp, err := p.llmClient.Generate(ctx, llmReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate updated plan: %w", err)
+	// References to the current task IDs resolve to the tasks with the same name
+	aliases := make(map[string]string, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		if task.Name != "" {
+			aliases[task.ID] = task.Name
+		}
 	}
-
-	// Parse the updated tasks
-	updatedTasks, err := p.parsePlanResponse(resp.Response)
+	
+	updatedTasks, err := p.generateTasks(ctx, llm.StageReplanning, prompt, aliases)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse updated plan response: %w", err)
+		return nil, fmt.Errorf("failed to update plan: %w", err)
 	}
 
 	// Update plan
-	plan.Tasks = updatedTasks
+	diff := DiffTasks(plan.Tasks, updatedTasks)
+	plan.Tasks = carryOverCompleted(plan.Tasks, updatedTasks, diff)
+	plan.Revision++
 	plan.UpdatedAt = time.Now()
 
 	// Store updated plan
 	if err := p.memory.Store(ctx, "plan:"+plan.ID, plan); err != nil {
 		p.logger.WithField("error", err).Warn("Failed to store updated plan in memory")
 	}
+	p.recordRevision(ctx, plan, history, feedback, diff)
 
-	p.logger.WithField("plan_id", planID).Info("Plan updated successfully")
+	p.logger.WithFields(map[string]interface{}{
+		"plan_id":  planID,
+		"revision": plan.Revision,
+		"added":    len(diff.Added),
+		"removed":  len(diff.Removed),
+		"modified": len(diff.Modified),
+	}).Info("Plan updated successfully")
 
 	return plan, nil
 }
@@ -154,34 +158,142 @@ Please provide a JSON response with the following structure:
 {
   "tasks": [
     {
-      "type": "browser|script|api|analysis",
+      "name": "open_page",
+      "type": "%[2]s",
       "description": "Clear description of what to do",
       "parameters": {
         "key": "value"
       },
-      "dependencies": ["task_id_1", "task_id_2"]
+      "dependencies": []
+    },
+    {
+      "name": "summarize_page",
+      "type": "%[2]s",
+      "description": "Clear description of what to do",
+      "parameters": {
+        "input": "{{ tasks.open_page.result }}"
+      },
+      "dependencies": ["open_page"]
     }
   ]
 }
 
-Task types:
-- browser: Web browser automation (navigation, clicking, form filling)
-- script: Execute a script or command
-- api: Make API calls
-- analysis: Analyze data or content
+%[3]s
 
 Make sure tasks are:
 1. Specific and actionable
 2. Properly ordered with dependencies
 3. Include all necessary parameters
 4. Realistic and achievable
+5. Named with a short unique snake_case name; "dependencies" lists the names
+   of the tasks that must finish first, and parameters may use
+   {{ tasks.<name>.result.<path> }} to read the result of a dependency
+
+Response:`, goal, strings.Join(p.taskTypes(), "|"), p.describeTaskTypes())
+}
+
+// defaultTaskTypes describes the built-in task types when no handler catalog is set
+var defaultTaskTypes = []interfaces.HandlerDescription{
+	{TaskType: "browser", Description: "Web browser automation (navigation, clicking, form filling)"},
+	{TaskType: "script", Description: "Execute a script or command"},
+	{TaskType: "api", Description: "Make API calls"},
+	{TaskType: "analysis", Description: "Analyze data or content"},
+}
+
+// handlerDescriptions returns the task types the executor can run
+func (p *TaskPlanner) handlerDescriptions() []interfaces.HandlerDescription {
+	if p.handlers != nil {
+		if descriptions := p.handlers.DescribeHandlers(); len(descriptions) > 0 {
+			return descriptions
+		}
+	}
+	return defaultTaskTypes
+}
+
+// taskTypes returns the names of the available task types
+func (p *TaskPlanner) taskTypes() []string {
+	descriptions := p.handlerDescriptions()
+	types := make([]string, len(descriptions))
+	for i, description := range descriptions {
+		types[i] = description.TaskType
+	}
+	return types
+}
+
+// describeTaskTypes renders the task types, their actions and parameter
+// schemas for the planning prompt
+func (p *TaskPlanner) describeTaskTypes() string {
+	return DescribeTaskTypes(p.handlerDescriptions())
+}
+
+// DescribeTaskTypes renders task types, their actions and parameter schemas
+// for an LLM prompt
+func DescribeTaskTypes(descriptions []interfaces.HandlerDescription) string {
+	var builder strings.Builder
+	builder.WriteString("Task types:")
+
+	hasSchemas := false
+	for _, description := range descriptions {
+		builder.WriteString("\n- " + description.TaskType)
+		if description.Description != "" {
+			builder.WriteString(": " + description.Description)
+		}
+		if len(description.Actions) > 0 {
+			builder.WriteString("\n  actions: " + strings.Join(description.Actions, ", "))
+		}
+		if description.Parameters != nil {
+			encoded, err := json.Marshal(description.Parameters)
+			if err == nil {
+				builder.WriteString("\n  parameters schema: " + string(encoded))
+				hasSchemas = true
+			}
+		}
+	}
+
+	if hasSchemas {
+		builder.WriteString("\n\nOnly use parameters defined in the schema of the task's type; tasks with unknown or mistyped parameters are rejected.")
+	}
+
+	return builder.String()
+}
 
-Response:`, goal)
+// currentTask is a task of the plan being updated as shown to the LLM. IDs
+// and timestamps are left out, so the same plan always gives the same prompt
+// and recorded updates can be replayed.
+type currentTask struct {
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	Description  string                 `json:"description"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty"`
+	Status       interfaces.TaskStatus  `json:"status"`
+	Dependencies []string               `json:"dependencies,omitempty"`
 }
 
 // buildUpdatePrompt creates a prompt for updating an existing plan
 func (p *TaskPlanner) buildUpdatePrompt(plan *interfaces.Plan, feedback string) string {
-	planJSON, _ := json.MarshalIndent(plan.Tasks, "", "  ")
+	names := make(map[string]string, len(plan.Tasks))
+	for _, task := range plan.Tasks {
+		names[task.ID] = taskLabel(task)
+	}
+
+	current := make([]currentTask, len(plan.Tasks))
+	for i, task := range plan.Tasks {
+		current[i] = currentTask{
+			Name:        taskLabel(task),
+			Type:        task.Type,
+			Description: task.Description,
+			Parameters:  task.Parameters,
+			Status:      task.Status,
+		}
+		for _, dep := range task.Dependencies {
+			name, ok := names[dep]
+			if !ok {
+				name = dep
+			}
+			current[i].Dependencies = append(current[i].Dependencies, name)
+		}
+	}
+	planJSON, _ := json.MarshalIndent(current, "", "  ")
 	
 	return fmt.Sprintf(`You are an AI task planner. Update the following plan based on the feedback provided.
 
@@ -192,51 +304,27 @@ Feedback: %s
 
 Please provide an updated JSON response with the same structure as the original plan.
 Consider the feedback and modify, add, or remove tasks as necessary.
+Keep the names of tasks you keep, and list dependencies by task name.
 
 Response:`, plan.Goal, string(planJSON), feedback)
 }
 
-// parsePlanResponse parses the LLM response into tasks
-func (p *TaskPlanner) parsePlanResponse(response string) ([]interfaces.Task, error) {
-	// Extract JSON from response (LLM might include extra text)
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}") + 1
-	
-	if jsonStart == -1 || jsonEnd == 0 {
-		return nil, fmt.Errorf("no valid JSON found in response")
-	}
-
-	jsonStr := response[jsonStart:jsonEnd]
-
-	// Parse JSON
-	var planData struct {
-		Tasks []struct {
-			Type         string                 `json:"type"`
-			Description  string                 `json:"description"`
-			Parameters   map[string]interface{} `json:"parameters"`
-			Dependencies []string               `json:"dependencies"`
-		} `json:"tasks"`
+// parsePlanResponse parses the LLM response into tasks, resolving dependency
+// references to task IDs and validating the resulting graph
+func (p *TaskPlanner) parsePlanResponse(response string, aliases map[string]string) ([]interfaces.Task, error) {
+	planned, err := decodePlanResponse(response)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal([]byte(jsonStr), &planData); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	// Rewrite names and positions to task IDs
+	tasks, err := resolveReferences(planned, aliases)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert to Task objects
-	tasks := make([]interfaces.Task, len(planData.Tasks))
-	now := time.Now()
-
-	for i, taskData := range planData.Tasks {
-		tasks[i] = interfaces.Task{
-			ID:           uuid.New().String(),
-			Type:         taskData.Type,
-			Description:  taskData.Description,
-			Parameters:   taskData.Parameters,
-			Status:       interfaces.TaskStatusPending,
-			Dependencies: taskData.Dependencies,
-			CreatedAt:    now,
-			UpdatedAt:    now,
-		}
+	if err := ValidateTasks(tasks, p.taskTypes()); err != nil {
+		return nil, err
 	}
 
 	return tasks, nil
@@ -0,0 +1,210 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	llmclient "github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/logger"
+	"github.com/ai-agent-framework/pkg/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedLLMClient returns the given responses in order and records prompts
+type scriptedLLMClient struct {
+	responses   []string
+	prompts     []string
+	formats     []interface{}
+	stages      []string
+	formatError error
+}
+
+func (c *scriptedLLMClient) Generate(ctx context.Context, request interfaces.LLMRequest) (*interfaces.LLMResponse, error) {
+	c.formats = append(c.formats, request.Format)
+	c.stages = append(c.stages, request.Stage)
+	if c.formatError != nil && request.Format != nil {
+		return nil, c.formatError
+	}
+	c.prompts = append(c.prompts, request.Prompt)
+	response := c.responses[0]
+	if len(c.responses) > 1 {
+		c.responses = c.responses[1:]
+	}
+	return &interfaces.LLMResponse{Model: "stub", Response: response, Done: true}, nil
+}
+
+func (c *scriptedLLMClient) Chat(ctx context.Context, request interfaces.ChatRequest) (*interfaces.ChatResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *scriptedLLMClient) Embed(ctx context.Context, request interfaces.EmbeddingRequest) (*interfaces.EmbeddingResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (c *scriptedLLMClient) IsHealthy(ctx context.Context) bool {
+	return true
+}
+
+func newTestPlanner(responses ...string) (*TaskPlanner, *scriptedLLMClient) {
+	log := logger.NewLogrusLogger("error")
+	llm := &scriptedLLMClient{responses: responses}
+	return NewTaskPlanner(llm, memory.NewInMemoryStore(log), log), llm
+}
+
+func TestCreatePlanResolvesNamesAndPositions(t *testing.T) {
+	planner, _ := newTestPlanner(`Here is the plan:
+{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open go.dev", "parameters": {"url": "https://go.dev"}},
+  {"name": "links", "type": "browser", "description": "Extract links", "dependencies": ["open"]},
+  {"type": "analysis", "description": "Summarize", "dependencies": [1, "2", "links"]}
+]}`)
+
+	plan, err := planner.CreatePlan(context.Background(), "Summarize go.dev")
+	require.NoError(t, err)
+	require.Len(t, plan.Tasks, 3)
+
+	open, links, summary := plan.Tasks[0], plan.Tasks[1], plan.Tasks[2]
+	assert.Equal(t, "task_3", summary.Name)
+	assert.Empty(t, open.Dependencies)
+	assert.Equal(t, []string{open.ID}, links.Dependencies)
+	assert.Equal(t, []string{open.ID, links.ID}, summary.Dependencies)
+}
+
+func TestCreatePlanRejectsInvalidReferences(t *testing.T) {
+	planner, _ := newTestPlanner(`{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open", "dependencies": ["task_id_1"]},
+  {"name": "open", "type": "web", "description": "Duplicate", "dependencies": ["open"]}
+]}`)
+
+	_, err := planner.CreatePlan(context.Background(), "goal")
+	require.Error(t, err)
+
+	var validationErr *PlanValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		`tasks 1 and 2 are both named "open"; task names must be unique`,
+		`task 1 ("open"): dependency "task_id_1" does not match any task; reference one of the task names: open, open`,
+	}, validationErr.Problems)
+}
+
+func TestCreatePlanRejectsDefaultNamesTakenByOtherTasks(t *testing.T) {
+	planner, _ := newTestPlanner(`{"tasks": [
+  {"type": "browser", "description": "Open"},
+  {"name": "task_1", "type": "analysis", "description": "Summarize", "dependencies": [1]}
+]}`)
+
+	_, err := planner.CreatePlan(context.Background(), "goal")
+
+	var validationErr *PlanValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, validationErr.Problems,
+		`task 1 has no name and its default name "task_1" is used by task 2; give task 1 a name`)
+}
+
+func TestCreatePlanRejectsExpressionsOutsideDependencies(t *testing.T) {
+	planner, _ := newTestPlanner(`{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open", "parameters": {"url": "https://go.dev"}},
+  {"name": "links", "type": "browser", "description": "Extract links"},
+  {"name": "summary", "type": "analysis", "description": "Summarize", "dependencies": ["open"],
+   "parameters": {"input": "{{ tasks.open.result.content }} {{ tasks.links.result }}"}}
+]}`)
+
+	_, err := planner.CreatePlan(context.Background(), "goal")
+
+	var validationErr *PlanValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		`task 3 ("summary"): parameter expression references "links", which is not a dependency of this task; add it to dependencies`,
+	}, validationErr.Problems)
+}
+
+func TestValidateTasksReportsCyclesAndUnknownTypes(t *testing.T) {
+	tasks := []interfaces.Task{
+		{ID: "a", Name: "fetch", Type: "api", Dependencies: []string{"c"}},
+		{ID: "b", Name: "parse", Type: "analysis", Dependencies: []string{"a"}},
+		{ID: "c", Name: "store", Type: "database", Dependencies: []string{"b", "missing"}},
+	}
+
+	err := ValidateTasks(tasks, []string{"api", "analysis"})
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		`task 3 ("store"): unknown task type "database"; use one of: analysis, api`,
+		`task 3 ("store"): dependency "missing" does not refer to a task in the plan`,
+		`dependency cycle fetch -> store -> parse -> fetch; remove one of these dependencies`,
+	}, err.(*PlanValidationError).Problems)
+}
+
+func TestUpdatePlanResolvesPreviousTaskIDs(t *testing.T) {
+	planner, llm := newTestPlanner(`{"tasks": [{"name": "open", "type": "browser", "description": "Open"}]}`)
+
+	ctx := context.Background()
+	plan, err := planner.CreatePlan(ctx, "goal")
+	require.NoError(t, err)
+
+	// The LLM refers to the kept task by its ID in the current plan
+	llm.responses = []string{`{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open"},
+  {"name": "summary", "type": "analysis", "description": "Summarize", "dependencies": ["` + plan.Tasks[0].ID + `"]}
+]}`}
+
+	updated, err := planner.UpdatePlan(ctx, plan.ID, "add a summary")
+	require.NoError(t, err)
+	require.Len(t, updated.Tasks, 2)
+	assert.Equal(t, []string{updated.Tasks[0].ID}, updated.Tasks[1].Dependencies)
+	assert.Contains(t, llm.prompts[1], "add a summary")
+	assert.Equal(t, []string{"planning", "replanning"}, llm.stages)
+}
+
+func TestCreatePlanRepairsMalformedResponses(t *testing.T) {
+	planner, llm := newTestPlanner(
+		`<think>The user wants {a plan}</think>{"tasks": [{"name": "open", "type": "browser", "description": "Open",}]}`,
+		`{"tasks": [{"name": "open", "type": "browser", "description": "Open"}, {"name": "sum", "type": "analysis", "description": "Sum", "dependencies": ["opne"]}]}`,
+		"```json\n{\"tasks\": [{\"name\": \"open\", \"type\": \"browser\", \"description\": \"Open\"}]}\n```",
+	)
+
+	plan, err := planner.CreatePlan(context.Background(), "goal")
+	require.NoError(t, err)
+	require.Len(t, plan.Tasks, 1)
+
+	require.Len(t, llm.prompts, 3)
+	assert.Contains(t, llm.prompts[1], "no complete, valid JSON object")
+	assert.NotContains(t, llm.prompts[1], "<think>")
+	assert.Contains(t, llm.prompts[2], `dependency "opne" does not match any task`)
+
+	// Every request asks for output matching the plan schema
+	require.NotNil(t, llm.formats[0])
+	assert.Equal(t, planSchema([]string{"browser", "script", "api", "analysis"}), llm.formats[0])
+}
+
+func TestCreatePlanGivesUpAfterRepairAttempts(t *testing.T) {
+	planner, llm := newTestPlanner(`I cannot help with that.`)
+	planner.SetRepairAttempts(1)
+
+	_, err := planner.CreatePlan(context.Background(), "goal")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 2 attempt(s)")
+	assert.Len(t, llm.prompts, 2)
+}
+
+func TestCreatePlanFallsBackWithoutStructuredOutput(t *testing.T) {
+	planner, llm := newTestPlanner(`{"tasks": [{"name": "open", "type": "browser", "description": "Open"}]}`)
+	llm.formatError = &llmclient.StatusError{Server: "Ollama", StatusCode: 400}
+
+	plan, err := planner.CreatePlan(context.Background(), "goal")
+	require.NoError(t, err)
+	assert.Len(t, plan.Tasks, 1)
+	assert.Equal(t, []interface{}{planSchema([]string{"browser", "script", "api", "analysis"}), nil}, llm.formats)
+}
+
+func TestCreatePlanKeepsStructuredOutputOnServerErrors(t *testing.T) {
+	planner, llm := newTestPlanner(`{"tasks": [{"name": "open", "type": "browser", "description": "Open"}]}`)
+	llm.formatError = &llmclient.StatusError{Server: "Ollama", StatusCode: 503}
+
+	_, err := planner.CreatePlan(context.Background(), "goal")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+	assert.Len(t, llm.formats, 1)
+}
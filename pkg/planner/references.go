@@ -0,0 +1,299 @@
+package planner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/executor"
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/google/uuid"
+)
+
+// planTask is a task as written by the LLM. Dependencies refer to other tasks
+// of the same plan by name or by 1-based position in the task list.
+type planTask struct {
+	Name         string                 `json:"name"`
+	Type         string                 `json:"type"`
+	Description  string                 `json:"description"`
+	Parameters   map[string]interface{} `json:"parameters"`
+	Dependencies []interface{}          `json:"dependencies"`
+}
+
+// PlanValidationError lists every problem that makes a plan unexecutable
+type PlanValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *PlanValidationError) Error() string {
+	return "invalid plan: " + strings.Join(e.Problems, "; ")
+}
+
+// resolveReferences assigns IDs to the planned tasks and rewrites their
+// dependency references to those IDs. aliases maps additional references,
+// such as task IDs of a previous plan version, to task names.
+func resolveReferences(planned []planTask, aliases map[string]string) ([]interfaces.Task, error) {
+	var problems []string
+	now := time.Now()
+
+	tasks := make([]interfaces.Task, len(planned))
+	byName := make(map[string]int, len(planned))
+
+	// Unnamed tasks are named after their position, which must not take the
+	// name of another task
+	named := make(map[string]int, len(planned))
+	for i := len(planned) - 1; i >= 0; i-- {
+		if name := strings.TrimSpace(planned[i].Name); name != "" {
+			named[name] = i
+		}
+	}
+
+	for i, taskData := range planned {
+		name := strings.TrimSpace(taskData.Name)
+		unnamed := name == ""
+		if unnamed {
+			name = fmt.Sprintf("task_%d", i+1)
+		}
+		if other, taken := named[name]; unnamed && taken {
+			problems = append(problems, fmt.Sprintf("task %d has no name and its default name %q is used by task %d; give task %d a name", i+1, name, other+1, i+1))
+		} else if first, exists := byName[name]; exists {
+			problems = append(problems, fmt.Sprintf("tasks %d and %d are both named %q; task names must be unique", first+1, i+1, name))
+		} else {
+			byName[name] = i
+		}
+
+		tasks[i] = interfaces.Task{
+			ID:          uuid.New().String(),
+			Name:        name,
+			Type:        taskData.Type,
+			Description: taskData.Description,
+			Parameters:  taskData.Parameters,
+			Status:      interfaces.TaskStatusPending,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+
+	names := make([]string, len(tasks))
+	for i := range tasks {
+		names[i] = tasks[i].Name
+	}
+
+	for i, taskData := range planned {
+		seen := make(map[int]bool)
+		for _, ref := range taskData.Dependencies {
+			target, ok := lookupReference(ref, byName, aliases, len(tasks))
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: dependency %s does not match any task; reference one of the task names: %s",
+					DescribeTask(i, tasks[i].Name), formatReference(ref), strings.Join(names, ", ")))
+				continue
+			}
+			if target == i {
+				problems = append(problems, fmt.Sprintf("%s: a task cannot depend on itself", DescribeTask(i, tasks[i].Name)))
+				continue
+			}
+			if !seen[target] {
+				seen[target] = true
+				tasks[i].Dependencies = append(tasks[i].Dependencies, tasks[target].ID)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &PlanValidationError{Problems: problems}
+	}
+
+	return tasks, nil
+}
+
+// lookupReference finds the index of the task a dependency reference points to
+func lookupReference(ref interface{}, byName map[string]int, aliases map[string]string, count int) (int, bool) {
+	switch r := ref.(type) {
+	case float64:
+		if r == float64(int(r)) && int(r) >= 1 && int(r) <= count {
+			return int(r) - 1, true
+		}
+	case string:
+		r = strings.TrimSpace(r)
+		if index, ok := byName[r]; ok {
+			return index, true
+		}
+		if name, ok := aliases[r]; ok {
+			if index, ok := byName[name]; ok {
+				return index, true
+			}
+		}
+		if n, err := strconv.Atoi(r); err == nil && n >= 1 && n <= count {
+			return n - 1, true
+		}
+	}
+	return 0, false
+}
+
+// ValidateTasks checks that the tasks of a plan can be executed: IDs are
+// unique, every dependency refers to a task of the plan, parameter
+// expressions only reference dependencies, the dependency graph has no cycles
+// and every task type is known. An empty taskTypes list skips the type check.
+func ValidateTasks(tasks []interfaces.Task, taskTypes []string) error {
+	var problems []string
+
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if task.ID == "" {
+			problems = append(problems, fmt.Sprintf("%s: missing task ID", DescribeTask(i, task.Name)))
+			continue
+		}
+		if first, exists := index[task.ID]; exists {
+			problems = append(problems, fmt.Sprintf("tasks %d and %d share the ID %q", first+1, i+1, task.ID))
+			continue
+		}
+		index[task.ID] = i
+	}
+
+	if len(taskTypes) > 0 {
+		known := make(map[string]bool, len(taskTypes))
+		for _, taskType := range taskTypes {
+			known[taskType] = true
+		}
+		sorted := append([]string(nil), taskTypes...)
+		sort.Strings(sorted)
+
+		for i, task := range tasks {
+			if !known[task.Type] {
+				problems = append(problems, fmt.Sprintf("%s: unknown task type %q; use one of: %s", DescribeTask(i, task.Name), task.Type, strings.Join(sorted, ", ")))
+			}
+		}
+	}
+
+	for i, task := range tasks {
+		for _, dep := range task.Dependencies {
+			if _, ok := index[dep]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: dependency %q does not refer to a task in the plan", DescribeTask(i, task.Name), dep))
+			} else if dep == task.ID {
+				problems = append(problems, fmt.Sprintf("%s: a task cannot depend on itself", DescribeTask(i, task.Name)))
+			}
+		}
+		for _, ref := range undeclaredReferences(task, tasks, index) {
+			problems = append(problems, fmt.Sprintf("%s: parameter expression references %q, which is not a dependency of this task; add it to dependencies",
+				DescribeTask(i, task.Name), ref))
+		}
+	}
+
+	if cycle := findCycle(tasks, index); cycle != nil {
+		labels := make([]string, len(cycle))
+		for i, position := range cycle {
+			labels[i] = taskLabel(tasks[position])
+		}
+		problems = append(problems, fmt.Sprintf("dependency cycle %s; remove one of these dependencies", strings.Join(labels, " -> ")))
+	}
+
+	if len(problems) > 0 {
+		return &PlanValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+// undeclaredReferences returns the tasks referenced by the parameter
+// expressions of task that are not among its dependencies. Dependencies are
+// matched by ID or name like the executor does when resolving the expressions.
+func undeclaredReferences(task interfaces.Task, tasks []interfaces.Task, index map[string]int) []string {
+	var undeclared []string
+	seen := make(map[string]bool)
+	for _, ref := range executor.TemplateReferences(task.Parameters) {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		declared := false
+		for _, depID := range task.Dependencies {
+			if position, ok := index[depID]; depID == ref || (ok && tasks[position].Name != "" && tasks[position].Name == ref) {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			undeclared = append(undeclared, ref)
+		}
+	}
+	return undeclared
+}
+
+// findCycle returns the positions of the tasks forming a dependency cycle,
+// starting and ending with the same task, or nil if the graph is acyclic
+func findCycle(tasks []interfaces.Task, index map[string]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(tasks))
+	var stack []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		stack = append(stack, i)
+
+		for _, dep := range tasks[i].Dependencies {
+			j, ok := index[dep]
+			if !ok || j == i {
+				continue
+			}
+			switch state[j] {
+			case visiting:
+				for start, position := range stack {
+					if position == j {
+						return append(append([]int(nil), stack[start:]...), j)
+					}
+				}
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[i] = done
+		return nil
+	}
+
+	for i := range tasks {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+
+	return nil
+}
+
+// DescribeTask identifies a task by position and name in error messages
+func DescribeTask(i int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("task %d", i+1)
+	}
+	return fmt.Sprintf("task %d (%q)", i+1, name)
+}
+
+// taskLabel names a task by its name, falling back to its ID
+func taskLabel(task interfaces.Task) string {
+	if task.Name != "" {
+		return task.Name
+	}
+	return task.ID
+}
+
+// formatReference renders a dependency reference as written in the plan
+func formatReference(ref interface{}) string {
+	if s, ok := ref.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(ref)
+}
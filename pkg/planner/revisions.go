@@ -0,0 +1,252 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+)
+
+// GetPlanHistory returns every revision of a plan, oldest first. Plans that
+// were never revised by the planner have a single revision.
+func (p *TaskPlanner) GetPlanHistory(ctx context.Context, planID string) ([]interfaces.PlanRevision, error) {
+	plan, err := p.GetPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := p.loadHistory(ctx, plan)
+	revisions := make([]interfaces.PlanRevision, len(history))
+	for i, revision := range history {
+		revisions[i] = copyRevision(revision)
+	}
+	return revisions, nil
+}
+
+// GetPlanRevision returns a single revision of a plan
+func (p *TaskPlanner) GetPlanRevision(ctx context.Context, planID string, revision int) (*interfaces.PlanRevision, error) {
+	history, err := p.GetPlanHistory(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range history {
+		if history[i].Number == revision {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("plan %s has no revision %d (latest is %d)", planID, revision, history[len(history)-1].Number)
+}
+
+// loadHistory returns the stored revisions of a plan. Plans created outside
+// the planner, such as plan files, start their history with their current
+// tasks.
+func (p *TaskPlanner) loadHistory(ctx context.Context, plan *interfaces.Plan) []interfaces.PlanRevision {
+	if data, err := p.memory.Retrieve(ctx, "plan_revisions:"+plan.ID); err == nil {
+		if history, ok := data.([]interfaces.PlanRevision); ok && len(history) > 0 {
+			return history
+		}
+	}
+
+	if plan.Revision == 0 {
+		plan.Revision = 1
+	}
+	return []interfaces.PlanRevision{newRevision(plan, "", nil)}
+}
+
+// recordRevision appends the current state of the plan to its history
+func (p *TaskPlanner) recordRevision(ctx context.Context, plan *interfaces.Plan, history []interfaces.PlanRevision, feedback string, diff *interfaces.PlanDiff) {
+	history = append(history, newRevision(plan, feedback, diff))
+	if err := p.memory.Store(ctx, "plan_revisions:"+plan.ID, history); err != nil {
+		p.logger.WithField("error", err).Warn("Failed to store plan revision in memory")
+	}
+}
+
+// newRevision snapshots the tasks of a plan
+func newRevision(plan *interfaces.Plan, feedback string, diff *interfaces.PlanDiff) interfaces.PlanRevision {
+	return interfaces.PlanRevision{
+		Number:    plan.Revision,
+		Tasks:     copyTasks(plan.Tasks),
+		Feedback:  feedback,
+		Diff:      diff,
+		CreatedAt: time.Now(),
+	}
+}
+
+// DiffTasks compares two versions of a plan's tasks. Tasks are matched by
+// name; a task is modified when its type, description, parameters or the
+// names of its dependencies differ.
+func DiffTasks(previous, revised []interfaces.Task) *interfaces.PlanDiff {
+	diff := &interfaces.PlanDiff{}
+
+	previousByLabel := make(map[string]int, len(previous))
+	for i, task := range previous {
+		previousByLabel[taskLabel(task)] = i
+	}
+	revisedLabels := make(map[string]bool, len(revised))
+
+	for _, task := range revised {
+		label := taskLabel(task)
+		revisedLabels[label] = true
+
+		i, ok := previousByLabel[label]
+		if !ok {
+			diff.Added = append(diff.Added, label)
+			continue
+		}
+		if fields := changedFields(previous[i], previous, task, revised); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, interfaces.TaskChange{Name: label, Fields: fields})
+		} else {
+			diff.Unchanged = append(diff.Unchanged, label)
+		}
+	}
+
+	for _, task := range previous {
+		if label := taskLabel(task); !revisedLabels[label] {
+			diff.Removed = append(diff.Removed, label)
+		}
+	}
+
+	return diff
+}
+
+// changedFields lists the fields that differ between two versions of a task
+func changedFields(before interfaces.Task, beforeTasks []interfaces.Task, after interfaces.Task, afterTasks []interfaces.Task) []string {
+	var fields []string
+	if before.Type != after.Type {
+		fields = append(fields, "type")
+	}
+	if before.Description != after.Description {
+		fields = append(fields, "description")
+	}
+	if !sameJSON(before.Parameters, after.Parameters) {
+		fields = append(fields, "parameters")
+	}
+	if !sameJSON(dependencyLabels(before, beforeTasks), dependencyLabels(after, afterTasks)) {
+		fields = append(fields, "dependencies")
+	}
+	return fields
+}
+
+// carryOverCompleted replaces revised tasks with their completed previous
+// version when neither they nor any of their dependencies changed, so work
+// that is still valid keeps its ID, status and result instead of running
+// again. Dependencies are rewritten to the IDs of the kept tasks.
+func carryOverCompleted(previous, revised []interfaces.Task, diff *interfaces.PlanDiff) []interfaces.Task {
+	completed := make(map[string]interfaces.Task)
+	for _, task := range previous {
+		if task.Status == interfaces.TaskStatusCompleted {
+			completed[taskLabel(task)] = task
+		}
+	}
+
+	keep := make(map[string]bool)
+	for _, label := range diff.Unchanged {
+		if _, ok := completed[label]; ok {
+			keep[label] = true
+		}
+	}
+
+	// A task whose dependency runs again has to run again as well
+	revisedByID := make(map[string]interfaces.Task, len(revised))
+	for _, task := range revised {
+		revisedByID[task.ID] = task
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, task := range revised {
+			label := taskLabel(task)
+			if !keep[label] {
+				continue
+			}
+			for _, dep := range task.Dependencies {
+				if !keep[taskLabel(revisedByID[dep])] {
+					delete(keep, label)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	redirect := make(map[string]string)
+	result := make([]interfaces.Task, len(revised))
+	for i, task := range revised {
+		label := taskLabel(task)
+		if !keep[label] {
+			result[i] = task
+			continue
+		}
+		kept := copyTask(completed[label])
+		kept.Dependencies = append([]string(nil), task.Dependencies...)
+		redirect[task.ID] = kept.ID
+		result[i] = kept
+	}
+
+	for i := range result {
+		for j, dep := range result[i].Dependencies {
+			if id, ok := redirect[dep]; ok {
+				result[i].Dependencies[j] = id
+			}
+		}
+	}
+
+	return result
+}
+
+// dependencyLabels returns the sorted names of a task's dependencies
+func dependencyLabels(task interfaces.Task, tasks []interfaces.Task) []string {
+	labels := make([]string, 0, len(task.Dependencies))
+	for _, dep := range task.Dependencies {
+		label := dep
+		for _, candidate := range tasks {
+			if candidate.ID == dep {
+				label = taskLabel(candidate)
+				break
+			}
+		}
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// sameJSON reports whether two values encode to the same JSON
+func sameJSON(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// copyRevision returns a revision whose tasks can be modified without
+// affecting the stored history
+func copyRevision(revision interfaces.PlanRevision) interfaces.PlanRevision {
+	revision.Tasks = copyTasks(revision.Tasks)
+	return revision
+}
+
+func copyTasks(tasks []interfaces.Task) []interfaces.Task {
+	copied := make([]interfaces.Task, len(tasks))
+	for i, task := range tasks {
+		copied[i] = copyTask(task)
+	}
+	return copied
+}
+
+// copyTask copies the maps and slices of a task that are modified during
+// execution
+func copyTask(task interfaces.Task) interfaces.Task {
+	if task.Parameters != nil {
+		params := make(map[string]interface{}, len(task.Parameters))
+		for key, value := range task.Parameters {
+			params[key] = value
+		}
+		task.Parameters = params
+	}
+	task.Dependencies = append([]string(nil), task.Dependencies...)
+	task.Attempts = append([]interfaces.TaskAttempt(nil), task.Attempts...)
+	return task
+}
@@ -0,0 +1,124 @@
+package planner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const initialPlan = `{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open", "parameters": {"url": "https://go.dev"}},
+  {"name": "links", "type": "browser", "description": "Extract links", "dependencies": ["open"]},
+  {"name": "summary", "type": "analysis", "description": "Summarize", "dependencies": ["links"]}
+]}`
+
+func TestUpdatePlanKeepsHistoryAndCompletedTasks(t *testing.T) {
+	planner, llm := newTestPlanner(initialPlan)
+
+	ctx := context.Background()
+	plan, err := planner.CreatePlan(ctx, "Summarize go.dev")
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.Revision)
+
+	open, links := plan.Tasks[0].ID, plan.Tasks[1].ID
+	plan.Tasks[0].Status = interfaces.TaskStatusCompleted
+	plan.Tasks[0].Result = "page"
+	plan.Tasks[1].Status = interfaces.TaskStatusCompleted
+	plan.Tasks[2].Status = interfaces.TaskStatusFailed
+
+	llm.responses = []string{`{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open", "parameters": {"url": "https://go.dev"}},
+  {"name": "links", "type": "browser", "description": "Extract links", "dependencies": ["open"]},
+  {"name": "summary", "type": "analysis", "description": "Summarize briefly", "dependencies": ["links"]},
+  {"name": "report", "type": "script", "description": "Print", "dependencies": ["summary"]}
+]}`}
+
+	updated, err := planner.UpdatePlan(ctx, plan.ID, "keep the summary short")
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Revision)
+	require.Len(t, updated.Tasks, 4)
+
+	// Unchanged completed tasks keep their IDs, status and results
+	assert.Equal(t, open, updated.Tasks[0].ID)
+	assert.Equal(t, "page", updated.Tasks[0].Result)
+	assert.Equal(t, links, updated.Tasks[1].ID)
+	assert.Equal(t, []string{open}, updated.Tasks[1].Dependencies)
+	assert.Equal(t, interfaces.TaskStatusPending, updated.Tasks[2].Status)
+	assert.Equal(t, []string{links}, updated.Tasks[2].Dependencies)
+	assert.Equal(t, []string{updated.Tasks[2].ID}, updated.Tasks[3].Dependencies)
+	require.NoError(t, ValidateTasks(updated.Tasks, nil))
+
+	history, err := planner.GetPlanHistory(ctx, plan.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 1, history[0].Number)
+	assert.Nil(t, history[0].Diff)
+	assert.Equal(t, interfaces.TaskStatusPending, history[0].Tasks[0].Status, "revisions are snapshots")
+	assert.Equal(t, "keep the summary short", history[1].Feedback)
+	assert.Equal(t, &interfaces.PlanDiff{
+		Added:     []string{"report"},
+		Modified:  []interfaces.TaskChange{{Name: "summary", Fields: []string{"description"}}},
+		Unchanged: []string{"open", "links"},
+	}, history[1].Diff)
+
+	// Modifying a returned revision must not change the history
+	history[0].Tasks[0].Name = "changed"
+	revision, err := planner.GetPlanRevision(ctx, plan.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "open", revision.Tasks[0].Name)
+
+	_, err = planner.GetPlanRevision(ctx, plan.ID, 3)
+	assert.EqualError(t, err, "plan "+plan.ID+" has no revision 3 (latest is 2)")
+}
+
+func TestUpdatePlanRerunsTasksDownstreamOfChanges(t *testing.T) {
+	planner, llm := newTestPlanner(initialPlan)
+
+	ctx := context.Background()
+	plan, err := planner.CreatePlan(ctx, "Summarize go.dev")
+	require.NoError(t, err)
+	for i := range plan.Tasks {
+		plan.Tasks[i].Status = interfaces.TaskStatusCompleted
+	}
+	links := plan.Tasks[1].ID
+
+	llm.responses = []string{`{"tasks": [
+  {"name": "open", "type": "browser", "description": "Open", "parameters": {"url": "https://go.dev/doc"}},
+  {"name": "links", "type": "browser", "description": "Extract links", "dependencies": ["open"]},
+  {"name": "summary", "type": "analysis", "description": "Summarize", "dependencies": ["links"]}
+]}`}
+
+	updated, err := planner.UpdatePlan(ctx, plan.ID, "use the docs page")
+	require.NoError(t, err)
+
+	for _, task := range updated.Tasks {
+		assert.Equal(t, interfaces.TaskStatusPending, task.Status, task.Name)
+	}
+	assert.NotEqual(t, links, updated.Tasks[1].ID)
+
+	revision, err := planner.GetPlanRevision(ctx, plan.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []interfaces.TaskChange{{Name: "open", Fields: []string{"parameters"}}}, revision.Diff.Modified)
+	assert.Equal(t, []string{"links", "summary"}, revision.Diff.Unchanged)
+}
+
+func TestDiffTasksComparesDependenciesByName(t *testing.T) {
+	previous := []interfaces.Task{
+		{ID: "a1", Name: "fetch", Type: "api"},
+		{ID: "b1", Name: "parse", Type: "analysis", Dependencies: []string{"a1"}},
+		{ID: "c1", Name: "store", Type: "script"},
+	}
+	revised := []interfaces.Task{
+		{ID: "a2", Name: "fetch", Type: "api"},
+		{ID: "b2", Name: "parse", Type: "analysis"},
+	}
+
+	diff := DiffTasks(previous, revised)
+	assert.Empty(t, diff.Added)
+	assert.Equal(t, []string{"store"}, diff.Removed)
+	assert.Equal(t, []interfaces.TaskChange{{Name: "parse", Fields: []string{"dependencies"}}}, diff.Modified)
+	assert.Equal(t, []string{"fetch"}, diff.Unchanged)
+}
@@ -0,0 +1,153 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ai-agent-framework/pkg/interfaces"
+	"github.com/ai-agent-framework/pkg/llm"
+	"github.com/ai-agent-framework/pkg/schema"
+)
+
+// defaultRepairAttempts bounds how often an unusable plan is sent back to the
+// LLM together with the problem before planning gives up
+const defaultRepairAttempts = 2
+
+// SetRepairAttempts sets how often an unusable plan response is sent back to
+// the LLM for correction (0 disables repair)
+func (p *TaskPlanner) SetRepairAttempts(attempts int) {
+	if attempts < 0 {
+		attempts = 0
+	}
+	p.repairAttempts = attempts
+}
+
+// generateTasks asks the LLM for a plan, constraining the output to the plan
+// schema, and re-prompts with the parse or validation error until the
+// response is usable or the repair attempts are exhausted. The model is left
+// to the client so it follows the configured default or stage route.
+func (p *TaskPlanner) generateTasks(ctx context.Context, stage, prompt string, aliases map[string]string) ([]interfaces.Task, error) {
+	llmReq := interfaces.LLMRequest{
+		Prompt: prompt,
+		Format: planSchema(p.taskTypes()),
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": 0.7,
+			"num_predict": 2000,
+		},
+		Stage: stage,
+	}
+
+	// Planning blocks the whole goal, so it is queued before other requests
+	ctx = llm.WithPriority(ctx, llm.PriorityHigh)
+
+	var lastErr error
+	for attempt := 1; attempt <= p.repairAttempts+1; attempt++ {
+		resp, err := llm.Generate(ctx, p.llmClient, llmReq)
+		if err != nil && llmReq.Format != nil && llm.IsRejected(err) {
+			// Servers without structured output support reject the format;
+			// fall back to relying on the prompt alone
+			p.logger.WithField("error", err).Warn("Structured plan request failed, retrying without an output format")
+			llmReq.Format = nil
+			resp, err = llm.Generate(ctx, p.llmClient, llmReq)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate plan: %w", err)
+		}
+
+		tasks, err := p.parsePlanResponse(resp.Response, aliases)
+		if err == nil {
+			return tasks, nil
+		}
+		lastErr = err
+
+		p.logger.WithFields(map[string]interface{}{
+			"attempt":      attempt,
+			"max_attempts": p.repairAttempts + 1,
+			"error":        err.Error(),
+		}).Warn("Plan response was unusable")
+
+		llmReq.Prompt = buildRepairPrompt(prompt, resp.Response, err)
+	}
+
+	return nil, fmt.Errorf("failed to parse plan response after %d attempt(s): %w", p.repairAttempts+1, lastErr)
+}
+
+// buildRepairPrompt asks the LLM to correct a response that could not be used
+func buildRepairPrompt(prompt, response string, problem error) string {
+	return fmt.Sprintf(`%s
+%s
+
+Your previous response could not be used:
+%s
+
+Respond again with only the corrected JSON object, fixing the problem above.
+
+Response:`, prompt, llm.StripThinking(response), problem.Error())
+}
+
+// planSchema returns the JSON schema plan responses must follow. Task types
+// are only constrained when taskTypes is given.
+func planSchema(taskTypes []string) map[string]interface{} {
+	typeSchema := map[string]interface{}{"type": "string"}
+	if len(taskTypes) > 0 {
+		types := make([]interface{}, len(taskTypes))
+		for i, taskType := range taskTypes {
+			types[i] = taskType
+		}
+		typeSchema["enum"] = types
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"tasks": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":        map[string]interface{}{"type": "string"},
+						"type":        typeSchema,
+						"description": map[string]interface{}{"type": "string", "minLength": 1},
+						"parameters":  map[string]interface{}{"type": "object"},
+						"dependencies": map[string]interface{}{
+							"type":  "array",
+							"items": map[string]interface{}{"type": []interface{}{"string", "integer"}},
+						},
+					},
+					"required": []interface{}{"type", "description"},
+				},
+			},
+		},
+		"required": []interface{}{"tasks"},
+	}
+}
+
+// decodePlanResponse extracts the plan object from an LLM response and checks
+// its structure. Task types are checked later by ValidateTasks, which reports
+// them with more context.
+func decodePlanResponse(response string) ([]planTask, error) {
+	encoded, err := llm.ExtractJSONObject(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(encoded), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if err := schema.Validate(planSchema(nil), document); err != nil {
+		return nil, fmt.Errorf("plan does not match the expected structure: %w", err)
+	}
+
+	var planData struct {
+		Tasks []planTask `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(encoded), &planData); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	return planData.Tasks, nil
+}
@@ -0,0 +1,304 @@
+// Package schema validates decoded JSON values against a subset of JSON Schema:
+// type (a name or a list of names), enum, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// minimum, maximum, anyOf and oneOf. Other keywords such as description are
+// ignored.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MustParse decodes a JSON schema document and panics if it is invalid. It is
+// meant for schemas declared as literals in code.
+func MustParse(document string) map[string]interface{} {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(document), &schema); err != nil {
+		panic(fmt.Sprintf("invalid schema: %v", err))
+	}
+	return schema
+}
+
+// ValidationError lists every problem found while validating a value
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate checks value against schema. Values are normalized through JSON
+// first so Go types such as []string or int validate like decoded JSON.
+func Validate(schema map[string]interface{}, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("value is not JSON encodable: %w", err)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return fmt.Errorf("value is not JSON encodable: %w", err)
+	}
+
+	v := &validator{}
+	v.validate(schema, normalized, "")
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validator accumulates problems while walking a value
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "value"
+	}
+	v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string) {
+	if schema == nil {
+		return
+	}
+
+	if types := typeNames(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, name := range types {
+			if hasType(value, name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.addf(path, "expected %s, got %s", strings.Join(types, " or "), typeOf(value))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addf(path, "must be one of %s", describeEnum(enum))
+		}
+	}
+
+	if alternatives, ok := schema["anyOf"].([]interface{}); ok && v.countMatches(alternatives, value, path) == 0 {
+		v.addf(path, "does not match any of the allowed schemas")
+	}
+	if alternatives, ok := schema["oneOf"].([]interface{}); ok {
+		if n := v.countMatches(alternatives, value, path); n != 1 {
+			v.addf(path, "must match exactly one of the allowed schemas (matched %d)", n)
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, typed, path)
+	case []interface{}:
+		v.validateArray(schema, typed, path)
+	case string:
+		length := len([]rune(typed))
+		if min, ok := number(schema["minLength"]); ok && float64(length) < min {
+			v.addf(path, "must be at least %v characters", min)
+		}
+		if max, ok := number(schema["maxLength"]); ok && float64(length) > max {
+			v.addf(path, "must be at most %v characters", max)
+		}
+	case float64:
+		if min, ok := number(schema["minimum"]); ok && typed < min {
+			v.addf(path, "must be >= %v", min)
+		}
+		if max, ok := number(schema["maximum"]); ok && typed > max {
+			v.addf(path, "must be <= %v", max)
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]interface{}, object map[string]interface{}, path string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, present := object[key]; !present {
+				v.addf(join(path, key), "is required")
+			}
+		}
+	}
+
+	// Visit keys in order so problems are reported deterministically
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if property, ok := properties[key].(map[string]interface{}); ok {
+			v.validate(property, object[key], join(path, key))
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addf(join(path, key), "is not a supported property%s", describeProperties(properties))
+			}
+		case map[string]interface{}:
+			v.validate(additional, object[key], join(path, key))
+		}
+	}
+}
+
+func (v *validator) validateArray(schema map[string]interface{}, array []interface{}, path string) {
+	if min, ok := number(schema["minItems"]); ok && float64(len(array)) < min {
+		v.addf(path, "must have at least %v items", min)
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(array)) > max {
+		v.addf(path, "must have at most %v items", max)
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range array {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// countMatches returns how many of the alternative schemas accept value
+func (v *validator) countMatches(alternatives []interface{}, value interface{}, path string) int {
+	matches := 0
+	for _, alternative := range alternatives {
+		schema, ok := alternative.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		probe := &validator{}
+		probe.validate(schema, value, path)
+		if len(probe.problems) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// typeNames reads the "type" keyword, which is either a name or a list of names
+func typeNames(raw interface{}) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		names := make([]string, 0, len(t))
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+// hasType reports whether a decoded JSON value has the named schema type
+func hasType(value interface{}, name string) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return false
+	}
+}
+
+// typeOf names the schema type of a decoded JSON value
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+func number(raw interface{}) (float64, bool) {
+	n, ok := raw.(float64)
+	return n, ok
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describeEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		encoded, _ := json.Marshal(value)
+		values[i] = string(encoded)
+	}
+	return strings.Join(values, ", ")
+}
+
+// describeProperties lists the known properties to help fix the mistake
+func describeProperties(properties map[string]interface{}) string {
+	if len(properties) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return " (expected one of: " + strings.Join(names, ", ") + ")"
+}
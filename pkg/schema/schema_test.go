@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = MustParse(`{
+	"type": "object",
+	"properties": {
+		"url": {"type": "string", "minLength": 1},
+		"mode": {"type": "string", "enum": ["fast", "slow"]},
+		"count": {"type": "integer", "minimum": 1, "maximum": 10},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"fields": {"type": ["array", "object"]},
+		"headers": {"type": "object", "additionalProperties": {"type": "string"}}
+	},
+	"required": ["url"],
+	"additionalProperties": false
+}`)
+
+func TestValidateAcceptsValidValues(t *testing.T) {
+	err := Validate(testSchema, map[string]interface{}{
+		"url":     "https://example.com",
+		"mode":    "fast",
+		"count":   3,
+		"tags":    []string{"a", "b"},
+		"fields":  map[string]interface{}{"name": "h2"},
+		"headers": map[string]string{"Accept": "application/json"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := Validate(testSchema, map[string]interface{}{
+		"mode":    "medium",
+		"count":   2.5,
+		"tags":    []interface{}{"a", 1, "c"},
+		"fields":  "name",
+		"headers": map[string]interface{}{"X-Retry": 3},
+		"extra":   true,
+	})
+	require.Error(t, err)
+
+	validationErr, ok := err.(*ValidationError)
+	require.True(t, ok)
+	assert.Equal(t, []string{
+		"url: is required",
+		"count: expected integer, got number",
+		"extra: is not a supported property (expected one of: count, fields, headers, mode, tags, url)",
+		"fields: expected array or object, got string",
+		"headers.X-Retry: expected string, got number",
+		"mode: must be one of \"fast\", \"slow\"",
+		"tags: must have at most 2 items",
+		"tags[1]: expected string, got number",
+	}, validationErr.Problems)
+}
+
+func TestValidateAnyOfAndOneOf(t *testing.T) {
+	schema := MustParse(`{"anyOf": [{"type": "string"}, {"type": "number", "minimum": 0}]}`)
+	assert.NoError(t, Validate(schema, "x"))
+	assert.NoError(t, Validate(schema, 4))
+	assert.Error(t, Validate(schema, -1))
+
+	schema = MustParse(`{"oneOf": [{"type": "number"}, {"type": "integer"}]}`)
+	assert.NoError(t, Validate(schema, 1.5))
+	assert.EqualError(t, Validate(schema, 2), "value: must match exactly one of the allowed schemas (matched 2)")
+}